@@ -0,0 +1,16 @@
+package flutter
+
+import "github.com/go-flutter-desktop/go-flutter/internal/embedding"
+
+// OptionBundle runs the application from a zip archive holding the
+// flutter_assets directory and the icudtl.dat file, typically compiled into
+// the executable by the go-flutter-bundle command. It replaces
+// ProjectAssetsPath and ApplicationICUDataPath.
+//
+// The archive is extracted once to a cache directory named after its
+// checksum. At the next starts the sizes of the extracted files are checked
+// against the archive, the bundle is extracted again when a file is missing
+// or has another size.
+func OptionBundle(archive []byte) Option {
+	return sharedOption(embedding.OptionBundle(archive))
+}
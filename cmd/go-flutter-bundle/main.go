@@ -0,0 +1,148 @@
+// Command go-flutter-bundle generates a Go file embedding the flutter_assets
+// directory and the icudtl.dat file, to be given to flutter.OptionBundle.
+//
+// Usage:
+//
+//	go-flutter-bundle -icu path/to/icudtl.dat [-assets build/flutter_assets] [-o flutter_bundle.go] [-package main] [-var flutterBundle]
+//
+// It can be invoked by go generate:
+//
+//	//go:generate go-flutter-bundle -icu ../flutter_engine/icudtl.dat
+package main
+
+import (
+	"archive/zip"
+	"bufio"
+	"bytes"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"strconv"
+
+	"github.com/pkg/errors"
+)
+
+// bytesPerLine is the number of bytes of the archive written on each line of
+// the generated Go file.
+const bytesPerLine = 64
+
+func main() {
+	assetsPath := flag.String("assets", "build/flutter_assets", "path to the flutter_assets directory")
+	icuDataPath := flag.String("icu", "", "path to the icudtl.dat file")
+	output := flag.String("o", "flutter_bundle.go", "path of the generated Go file")
+	packageName := flag.String("package", "main", "package of the generated Go file")
+	varName := flag.String("var", "flutterBundle", "name of the generated variable")
+	flag.Parse()
+
+	if *icuDataPath == "" {
+		fmt.Fprintln(os.Stderr, "go-flutter-bundle: the -icu flag is required")
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	archive, err := buildArchive(*assetsPath, *icuDataPath)
+	if err != nil {
+		log.Fatalf("go-flutter-bundle: %v", err)
+	}
+	err = writeGoFile(*output, *packageName, *varName, archive)
+	if err != nil {
+		log.Fatalf("go-flutter-bundle: %v", err)
+	}
+}
+
+// buildArchive zips the assets under flutter_assets/ and the ICU data as
+// icudtl.dat, the layout expected by flutter.OptionBundle.
+func buildArchive(assetsPath, icuDataPath string) ([]byte, error) {
+	info, err := os.Stat(assetsPath)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to stat assets path")
+	}
+	if !info.IsDir() {
+		return nil, errors.Errorf("assets path %s is not a directory", assetsPath)
+	}
+
+	var buf bytes.Buffer
+	w := zip.NewWriter(&buf)
+	err = filepath.Walk(assetsPath, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.IsDir() {
+			return nil
+		}
+		rel, err := filepath.Rel(assetsPath, path)
+		if err != nil {
+			return err
+		}
+		return addFile(w, "flutter_assets/"+filepath.ToSlash(rel), path)
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to archive assets")
+	}
+	err = addFile(w, "icudtl.dat", icuDataPath)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to archive icu data")
+	}
+	err = w.Close()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to write archive")
+	}
+	return buf.Bytes(), nil
+}
+
+func addFile(w *zip.Writer, name string, path string) error {
+	src, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+	// A fixed header, without modification time, keeps the output
+	// reproducible.
+	dst, err := w.CreateHeader(&zip.FileHeader{
+		Name:   name,
+		Method: zip.Deflate,
+	})
+	if err != nil {
+		return err
+	}
+	_, err = io.Copy(dst, src)
+	return err
+}
+
+// writeGoFile writes the archive as a slice of string literals, of
+// bytesPerLine bytes each, joined at init. A single string literal of the
+// whole archive would be a line of several megabytes that editors and diff
+// tools can't handle, and a byte slice literal is slow to compile.
+func writeGoFile(path, packageName, varName string, archive []byte) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return errors.Wrap(err, "failed to create Go file")
+	}
+	w := bufio.NewWriter(f)
+	fmt.Fprintf(w, "// Code generated by go-flutter-bundle. DO NOT EDIT.\n\n")
+	fmt.Fprintf(w, "package %s\n\n", packageName)
+	fmt.Fprintf(w, "import \"strings\"\n\n")
+	fmt.Fprintf(w, "// %s is a bundle archive to be given to flutter.OptionBundle.\n", varName)
+	fmt.Fprintf(w, "var %s = []byte(strings.Join([]string{\n", varName)
+	for start := 0; start < len(archive); start += bytesPerLine {
+		end := start + bytesPerLine
+		if end > len(archive) {
+			end = len(archive)
+		}
+		fmt.Fprintf(w, "\t%s,\n", strconv.Quote(string(archive[start:end])))
+	}
+	fmt.Fprintf(w, "}, \"\"))\n")
+	err = w.Flush()
+	if err != nil {
+		f.Close()
+		return errors.Wrap(err, "failed to write Go file")
+	}
+	err = f.Close()
+	if err != nil {
+		return errors.Wrap(err, "failed to write Go file")
+	}
+	return nil
+}
@@ -0,0 +1,52 @@
+package flutter
+
+import "github.com/go-gl/glfw/v3.2/glfw"
+
+// contentScale converts the screen coordinates of a window, in which glfw
+// reports the cursor positions and the window size, to the pixels of its
+// framebuffer, in which flutter expects the pointer positions.
+//
+// glfw 3.2 has no content scale query, the scale is the ratio between the
+// framebuffer size and the window size. It is fractional on the platforms
+// scaling the screen coordinates, e.g.: 1.5 for a 150% display scale, and
+// computed for each axis.
+type contentScale struct {
+	x float64
+	y float64
+}
+
+// newContentScale returns the scale of a window of width x height screen
+// coordinates having a framebuffer of widthPx x heightPx pixels. An empty
+// window, e.g.: minimized, has a scale of 1.
+func newContentScale(width int, height int, widthPx int, heightPx int) contentScale {
+	s := contentScale{x: 1.0, y: 1.0}
+	if width > 0 && widthPx > 0 {
+		s.x = float64(widthPx) / float64(width)
+	}
+	if height > 0 && heightPx > 0 {
+		s.y = float64(heightPx) / float64(height)
+	} else {
+		s.y = s.x
+	}
+	return s
+}
+
+// sizedWindow is the part of *glfw.Window giving its sizes.
+type sizedWindow interface {
+	GetSize() (width int, height int)
+	GetFramebufferSize() (width int, height int)
+}
+
+var _ sizedWindow = &glfw.Window{} // compile-time type check
+
+// windowContentScale returns the current scale of the window.
+func windowContentScale(window sizedWindow) contentScale {
+	width, height := window.GetSize()
+	widthPx, heightPx := window.GetFramebufferSize()
+	return newContentScale(width, height, widthPx, heightPx)
+}
+
+// toPixels maps a point in screen coordinates to the framebuffer.
+func (s contentScale) toPixels(x float64, y float64) (float64, float64) {
+	return x * s.x, y * s.y
+}
@@ -0,0 +1,126 @@
+package flutter
+
+import (
+	"testing"
+
+	"github.com/go-flutter-desktop/go-flutter/embedder"
+	"github.com/go-gl/glfw/v3.2/glfw"
+)
+
+// scaledWindow is a window of 800x600 screen coordinates whose framebuffer
+// is scaled by scaleX and scaleY.
+type scaledWindow struct {
+	scaleX  float64
+	scaleY  float64
+	cursorX float64
+	cursorY float64
+}
+
+func (w *scaledWindow) GetSize() (int, int) {
+	return 800, 600
+}
+
+func (w *scaledWindow) GetFramebufferSize() (int, int) {
+	return int(800 * w.scaleX), int(600 * w.scaleY)
+}
+
+func (w *scaledWindow) GetCursorPos() (float64, float64) {
+	return w.cursorX, w.cursorY
+}
+
+func (w *scaledWindow) GetMouseButton(button glfw.MouseButton) glfw.Action {
+	return glfw.Release
+}
+
+var _ pointerWindow = &scaledWindow{} // compile-time type check
+
+// testScales are the scales of the tests, with unequal x and y scales to
+// catch the mixed up axes.
+var testScales = []struct {
+	name   string
+	scaleX float64
+	scaleY float64
+}{
+	{"1.0", 1.0, 1.0},
+	{"1.25", 1.25, 1.25},
+	{"1.5", 1.5, 1.5},
+	{"2.0", 2.0, 2.0},
+	{"1.5x1.25", 1.5, 1.25},
+	{"2.0x1.0", 2.0, 1.0},
+}
+
+func TestContentScale(t *testing.T) {
+	for _, test := range testScales {
+		t.Run(test.name, func(t *testing.T) {
+			window := &scaledWindow{scaleX: test.scaleX, scaleY: test.scaleY}
+			scale := windowContentScale(window)
+			if scale.x != test.scaleX || scale.y != test.scaleY {
+				t.Fatalf("scale %vx%v, expected %vx%v", scale.x, scale.y, test.scaleX, test.scaleY)
+			}
+			x, y := scale.toPixels(100, 40)
+			if x != 100*test.scaleX || y != 40*test.scaleY {
+				t.Fatalf("(100, 40) converted to (%v, %v), expected (%v, %v)", x, y, 100*test.scaleX, 40*test.scaleY)
+			}
+		})
+	}
+
+	scale := newContentScale(0, 0, 0, 0)
+	if scale.x != 1.0 || scale.y != 1.0 {
+		t.Fatalf("empty window scale %vx%v, expected 1x1", scale.x, scale.y)
+	}
+}
+
+// testPointer returns a windowPointer recording the events it sends.
+func testPointer(events *[]embedder.PointerEvent) *windowPointer {
+	return &windowPointer{
+		sendPointerEvent: func(event embedder.PointerEvent) embedder.Result {
+			*events = append(*events, event)
+			return embedder.KSuccess
+		},
+		scrollAmount: defaultScrollAmount,
+	}
+}
+
+func TestPointerContentScale(t *testing.T) {
+	for _, test := range testScales {
+		t.Run(test.name, func(t *testing.T) {
+			window := &scaledWindow{scaleX: test.scaleX, scaleY: test.scaleY, cursorX: 100, cursorY: 40}
+			wantX, wantY := 100*test.scaleX, 40*test.scaleY
+
+			var events []embedder.PointerEvent
+			pointer := testPointer(&events)
+			pointer.handleCursorPos(window, 100, 40)
+			pointer.handleMouseButton(window, glfw.MouseButtonLeft, glfw.Press)
+			pointer.handleScroll(window, 0, -1)
+
+			expected := []struct {
+				phase  embedder.PointerPhase
+				signal embedder.PointerSignalKind
+			}{
+				{embedder.KAdd, embedder.PointerSignalKindNone},
+				{embedder.KHover, embedder.PointerSignalKindNone},
+				{embedder.KDown, embedder.PointerSignalKindNone},
+				{embedder.KMove, embedder.PointerSignalKindScroll},
+			}
+			if len(events) != len(expected) {
+				t.Fatalf("%d events sent, expected %d: %+v", len(events), len(expected), events)
+			}
+			for i, event := range events {
+				if event.Phase != expected[i].phase || event.SignalKind != expected[i].signal {
+					t.Errorf("event %d: phase %d signal %d, expected phase %d signal %d",
+						i, event.Phase, event.SignalKind, expected[i].phase, expected[i].signal)
+				}
+				if event.X != wantX || event.Y != wantY {
+					t.Errorf("event %d at (%v, %v), expected (%v, %v)", i, event.X, event.Y, wantX, wantY)
+				}
+			}
+			if events[2].Buttons != embedder.PointerButtonMousePrimary {
+				t.Errorf("down event buttons %d, expected %d", events[2].Buttons, embedder.PointerButtonMousePrimary)
+			}
+			scroll := events[3]
+			if scroll.ScrollDeltaX != 0 || scroll.ScrollDeltaY != defaultScrollAmount*test.scaleY {
+				t.Errorf("scroll delta (%v, %v), expected (0, %v)", scroll.ScrollDeltaX, scroll.ScrollDeltaY, defaultScrollAmount*test.scaleY)
+			}
+		})
+	}
+}
@@ -0,0 +1,132 @@
+package embedder
+
+// #include "flutter_embedder.h"
+// #include <stdlib.h>
+// const uint8_t *mapSnapshotFile(const char *path, bool executable, size_t *size);
+// const uint8_t *mapSnapshotBytes(const void *data, size_t size, bool executable);
+// void unmapSnapshot(const uint8_t *buffer, size_t size, bool fromFile);
+import "C"
+import (
+	"fmt"
+	"os"
+	"unsafe"
+
+	"github.com/pkg/errors"
+)
+
+// SnapshotBuffer is a buffer of an AOT snapshot. The file at Path is
+// memory-mapped when Path is set, Data is copied otherwise.
+type SnapshotBuffer struct {
+	Path string
+	Data []byte
+}
+
+// AOTSnapshot holds the buffers of an application compiled ahead of time,
+// for release builds. See
+// https://github.com/flutter/flutter/wiki/Flutter-engine-operation-in-AOT-Mode
+type AOTSnapshot struct {
+	VMData              SnapshotBuffer
+	VMInstructions      SnapshotBuffer
+	IsolateData         SnapshotBuffer
+	IsolateInstructions SnapshotBuffer
+}
+
+// mappedSnapshot is a SnapshotBuffer mapped in memory for the lifetime of
+// the engine.
+type mappedSnapshot struct {
+	buffer   *C.uint8_t
+	size     C.size_t
+	fromFile bool
+}
+
+// AOTSnapshotError is returned by LoadAOTSnapshot when a buffer of the AOT
+// snapshot can't be mapped in memory.
+type AOTSnapshotError struct {
+	// Snapshot is the name of the buffer, e.g.: "vm snapshot data".
+	Snapshot string
+	// Path is the file of the buffer, empty for an in-memory buffer.
+	Path string
+	Err  error
+}
+
+// Error implements the error interface.
+func (e *AOTSnapshotError) Error() string {
+	if e.Path == "" {
+		return fmt.Sprintf("failed to load the %s: %v", e.Snapshot, e.Err)
+	}
+	return fmt.Sprintf("failed to load the %s %s: %v", e.Snapshot, e.Path, e.Err)
+}
+
+// mapSnapshot maps the buffer in memory, the instructions buffers are
+// executable.
+func mapSnapshot(buffer SnapshotBuffer, executable bool) (*mappedSnapshot, error) {
+	if buffer.Path != "" {
+		_, err := os.Stat(buffer.Path)
+		if err != nil {
+			return nil, err
+		}
+		cPath := C.CString(buffer.Path)
+		defer C.free(unsafe.Pointer(cPath))
+		m := &mappedSnapshot{fromFile: true}
+		m.buffer = C.mapSnapshotFile(cPath, C.bool(executable), &m.size)
+		if m.buffer == nil {
+			return nil, errors.New("failed to map the file in memory")
+		}
+		return m, nil
+	}
+	if len(buffer.Data) == 0 {
+		return nil, errors.New("empty snapshot buffer")
+	}
+	m := &mappedSnapshot{size: C.size_t(len(buffer.Data))}
+	m.buffer = C.mapSnapshotBytes(unsafe.Pointer(&buffer.Data[0]), m.size, C.bool(executable))
+	if m.buffer == nil {
+		return nil, errors.New("failed to map the buffer in memory")
+	}
+	return m, nil
+}
+
+func (m *mappedSnapshot) unmap() {
+	C.unmapSnapshot(m.buffer, m.size, C.bool(m.fromFile))
+}
+
+// LoadAOTSnapshot maps the buffers of the AOTSnapshot in memory, until the
+// engine is shut down. It must be called before Run when the AOTSnapshot is
+// set, the error of a failure is an *AOTSnapshotError.
+func (flu *FlutterEngine) LoadAOTSnapshot() error {
+	if flu.AOTSnapshot == nil || flu.mappedSnapshots != nil {
+		return nil
+	}
+	buffers := []struct {
+		name       string
+		buffer     SnapshotBuffer
+		executable bool
+	}{
+		{"vm snapshot data", flu.AOTSnapshot.VMData, false},
+		{"vm snapshot instructions", flu.AOTSnapshot.VMInstructions, true},
+		{"isolate snapshot data", flu.AOTSnapshot.IsolateData, false},
+		{"isolate snapshot instructions", flu.AOTSnapshot.IsolateInstructions, true},
+	}
+	var mapped []*mappedSnapshot
+	for _, b := range buffers {
+		m, err := mapSnapshot(b.buffer, b.executable)
+		if err != nil {
+			for _, m := range mapped {
+				m.unmap()
+			}
+			return &AOTSnapshotError{Snapshot: b.name, Path: b.buffer.Path, Err: err}
+		}
+		mapped = append(mapped, m)
+	}
+	flu.mappedSnapshots = mapped
+	return nil
+}
+
+// setAOTSnapshot sets the buffers mapped by LoadAOTSnapshot in the project
+// arguments.
+func (flu *FlutterEngine) setAOTSnapshot(args *C.FlutterProjectArgs) {
+	m := flu.mappedSnapshots
+	args.vm_snapshot_data, args.vm_snapshot_data_size = m[0].buffer, m[0].size
+	args.vm_snapshot_instructions, args.vm_snapshot_instructions_size = m[1].buffer, m[1].size
+	args.isolate_snapshot_data, args.isolate_snapshot_data_size = m[2].buffer, m[2].size
+	args.isolate_snapshot_instructions, args.isolate_snapshot_instructions_size = m[3].buffer, m[3].size
+}
@@ -1,3 +1,11 @@
+// Package embedder wraps the C embedder API of the flutter engine.
+//
+// library/flutter_embedder.h is an unmodified copy of
+// shell/platform/embedder/embedder.h from the engine revision shipped with
+// the Flutter v1.9.1 stable release, which is the minimum engine supported.
+// Update it by copying the header of a newer engine revision as a whole,
+// never by editing it: the structs are read by the engine according to their
+// size.
 package embedder
 
 /*
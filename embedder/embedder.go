@@ -1,13 +1,15 @@
 package embedder
 
 // #include "flutter_embedder.h"
-// FlutterEngineResult runFlutter(uintptr_t window, FlutterEngine *engine, FlutterProjectArgs * Args,
-//						 const char *const * vmArgs, int nVmAgrs);
+// #include <stdlib.h>
+// FlutterEngineResult runFlutter(uintptr_t userData, FlutterRendererType rendererType, bool fboResetAfterPresent,
+//						 FlutterEngine *engine, FlutterProjectArgs * Args, const char *const * vmArgs, int nVmAgrs);
+// FlutterEngineResult createMessageResponseHandle(FlutterEngine engine, uintptr_t userData,
+//						 FlutterPlatformMessageResponseHandle **responseHandle);
 // char** makeCharArray(int size);
 // void setArrayString(char **a, char *s, int n);
 import "C"
 import (
-	"encoding/json"
 	"sync"
 	"unsafe"
 )
@@ -26,7 +28,15 @@ func FlutterEngineByIndex(index int) *FlutterEngine {
 
 // CountFlutterEngines return the number of engines registered in this embedder.
 func CountFlutterEngines() int {
-	return len(flutterEngines)
+	flutterEnginesLock.RLock()
+	defer flutterEnginesLock.RUnlock()
+	count := 0
+	for _, engine := range flutterEngines {
+		if engine != nil {
+			count++
+		}
+	}
+	return count
 }
 
 // Result corresponds to the C.enum retuned by the shared flutter library
@@ -40,6 +50,16 @@ const (
 	KInvalidArguments      Result = C.kInvalidArguments
 )
 
+// RendererType corresponds to the C.enum describing the renderer used by the
+// Flutter engine.
+type RendererType int32
+
+// Values representing the renderer of the engine.
+const (
+	KOpenGL   RendererType = C.kOpenGL
+	KSoftware RendererType = C.kSoftware
+)
+
 // FlutterEngine corresponds to the C.FlutterEngine with his associated callback's method.
 type FlutterEngine struct {
 	// Flutter Engine.
@@ -48,19 +68,49 @@ type FlutterEngine struct {
 	// index of the engine in the global flutterEngines slice
 	index int
 
-	// Necessary callbacks for rendering.
-	FMakeCurrent         func(v unsafe.Pointer) bool
-	FClearCurrent        func(v unsafe.Pointer) bool
-	FPresent             func(v unsafe.Pointer) bool
-	FFboCallback         func(v unsafe.Pointer) int32
-	FMakeResourceCurrent func(v unsafe.Pointer) bool
+	// Renderer used by the engine, defaults to KOpenGL.
+	RendererType RendererType
+
+	// Necessary callbacks for rendering with KOpenGL.
+	FMakeCurrent         func() bool
+	FClearCurrent        func() bool
+	FPresent             func() bool
+	FFboCallback         func() uint32
+	FMakeResourceCurrent func() bool
+	FGLProcResolver      func(procName string) unsafe.Pointer
+
+	// FBOResetAfterPresent asks the engine to call FFboCallback after every
+	// FPresent, instead of once, when the target FBO changes between frames.
+	FBOResetAfterPresent bool
+
+	// Optional callback for rendering with KOpenGL, it supplies the GL
+	// texture of an external texture having a frame available. It is called
+	// by the render thread with the GL context current.
+	FGLExternalTextureFrameCallback func(textureID int64, width int, height int) (texture GLTexture, ok bool)
+
+	// Optional callback for rendering with KOpenGL, it returns the
+	// transformation applied to the surface before rendering. It is called
+	// by the render thread.
+	FSurfaceTransformation func() Transformation
+
+	// Necessary callback for rendering with KSoftware. The buffer holds a
+	// copy of the pixels of the frame in the N32 format of the engine, BGRA
+	// on little endian platforms, rowBytes long rows.
+	FSurfacePresent func(buffer []byte, rowBytes int, height int) bool
 
 	// platform message callback.
-	FPlatfromMessage func(message *PlatformMessage, window unsafe.Pointer) bool
+	FPlatfromMessage func(message *PlatformMessage) bool
 
 	// Engine arguments
 	AssetsPath  string
 	IcuDataPath string
+
+	// AOTSnapshot holds the snapshots of an application compiled ahead of
+	// time. It is required by release builds of the engine.
+	AOTSnapshot *AOTSnapshot
+
+	// snapshots mapped in memory until the engine is shut down.
+	mappedSnapshots []*mappedSnapshot
 }
 
 // NewFlutterEngine creates an empty FlutterEngine
@@ -79,8 +129,14 @@ func (flu *FlutterEngine) Index() int {
 	return flu.index
 }
 
-// Run launches the Flutter Engine in a background thread.
-func (flu *FlutterEngine) Run(window uintptr, vmArgs []string) Result {
+// Run launches the Flutter Engine in a background thread. The thread calling
+// Run becomes the platform thread of the engine: the callbacks of the
+// FlutterEngine, except the rendering ones, are called on this thread by
+// FlutterEngineFlushPendingTasksNow.
+//
+// The AOTSnapshot must be loaded by LoadAOTSnapshot before Run, Run returns
+// KInvalidArguments when it isn't.
+func (flu *FlutterEngine) Run(vmArgs []string) Result {
 	// validate this FlutterEngine was created correctly
 	flutterEnginesLock.RLock()
 	if len(flutterEngines) <= flu.index || flutterEngines[flu.index] != flu {
@@ -95,25 +151,60 @@ func (flu *FlutterEngine) Run(window uintptr, vmArgs []string) Result {
 
 	args.struct_size = C.size_t(unsafe.Sizeof(args))
 
+	if flu.AOTSnapshot != nil {
+		if flu.mappedSnapshots == nil {
+			return KInvalidArguments
+		}
+		flu.setAOTSnapshot(&args)
+	}
+
 	cVMArgs := C.makeCharArray(C.int(len(vmArgs)))
 	for i, s := range vmArgs {
 		C.setArrayString(cVMArgs, C.CString(s), C.int(i))
 	}
 
-	res := C.runFlutter(C.uintptr_t(window), &flu.Engine, &args, cVMArgs, C.int(len(vmArgs)))
+	res := C.runFlutter(C.uintptr_t(flu.index), C.FlutterRendererType(flu.RendererType), C.bool(flu.FBOResetAfterPresent), &flu.Engine, &args, cVMArgs, C.int(len(vmArgs)))
+	if (Result)(res) != KSuccess {
+		flu.unmapSnapshots()
+		return (Result)(res)
+	}
 	if flu.Engine == nil {
+		flu.unmapSnapshots()
 		return KInvalidArguments
 	}
 
-	return (Result)(res)
+	return KSuccess
 }
 
-// Shutdown stops the Flutter engine.
+// Shutdown stops the Flutter engine. The reply callbacks of the messages
+// left unanswered are called with nil.
 func (flu *FlutterEngine) Shutdown() Result {
 	res := C.FlutterEngineShutdown(flu.Engine)
+	flu.unmapSnapshots()
+	flu.cancelReplies()
 	return (Result)(res)
 }
 
+// Release removes the FlutterEngine from the engines of this embedder, it
+// must be called once the engine is shut down or has failed to run. The
+// index of the engine isn't reused.
+func (flu *FlutterEngine) Release() {
+	flutterEnginesLock.Lock()
+	if flu.index < len(flutterEngines) && flutterEngines[flu.index] == flu {
+		flutterEngines[flu.index] = nil
+	}
+	flutterEnginesLock.Unlock()
+	flu.unmapSnapshots()
+	flu.cancelReplies()
+}
+
+func (flu *FlutterEngine) unmapSnapshots() {
+	for _, m := range flu.mappedSnapshots {
+		m.unmap()
+	}
+	flu.mappedSnapshots = nil
+}
+
 // PointerPhase corresponds to the C.enum describing phase of the mouse pointer.
 type PointerPhase int32
 
@@ -123,24 +214,77 @@ const (
 	KUp     PointerPhase = C.kUp
 	KDown   PointerPhase = C.kDown
 	KMove   PointerPhase = C.kMove
+	KAdd    PointerPhase = C.kAdd
+	KRemove PointerPhase = C.kRemove
+	KHover  PointerPhase = C.kHover
+)
+
+// PointerDeviceKind corresponds to the C.enum describing the device that
+// generated a pointer event.
+type PointerDeviceKind int32
+
+// Values representing the pointer device kind. When left to zero, the device
+// is treated as a mouse having its primary button pressed during KDown and
+// KMove.
+const (
+	PointerDeviceKindMouse PointerDeviceKind = C.kFlutterPointerDeviceKindMouse
+	PointerDeviceKindTouch PointerDeviceKind = C.kFlutterPointerDeviceKindTouch
+)
+
+// PointerButtonMouse corresponds to the C.enum describing the pressed mouse
+// buttons, as flags.
+type PointerButtonMouse int64
+
+// Values representing the first mouse buttons. The flags aren't limited to
+// them: the button n of the mouse, counting from 0, is the flag 1 << n.
+const (
+	PointerButtonMousePrimary   PointerButtonMouse = C.kFlutterPointerButtonMousePrimary
+	PointerButtonMouseSecondary PointerButtonMouse = C.kFlutterPointerButtonMouseSecondary
+	PointerButtonMouseMiddle    PointerButtonMouse = C.kFlutterPointerButtonMouseMiddle
+	PointerButtonMouseBack      PointerButtonMouse = C.kFlutterPointerButtonMouseBack
+	PointerButtonMouseForward   PointerButtonMouse = C.kFlutterPointerButtonMouseForward
+)
+
+// PointerSignalKind corresponds to the C.enum describing the signal carried
+// by a pointer event.
+type PointerSignalKind int32
+
+// Values representing the pointer signal kind.
+const (
+	PointerSignalKindNone   PointerSignalKind = C.kFlutterPointerSignalKindNone
+	PointerSignalKindScroll PointerSignalKind = C.kFlutterPointerSignalKindScroll
 )
 
 // PointerEvent represents the position and phase of the mouse at a given time.
+// Timestamp is in microseconds. The scroll deltas of a PointerSignalKindScroll
+// signal are in physical pixels.
 type PointerEvent struct {
-	Phase     PointerPhase
-	Timestamp int64
-	X         float64
-	Y         float64
+	Phase        PointerPhase
+	Timestamp    int64
+	X            float64
+	Y            float64
+	Device       int32
+	SignalKind   PointerSignalKind
+	ScrollDeltaX float64
+	ScrollDeltaY float64
+	DeviceKind   PointerDeviceKind
+	Buttons      PointerButtonMouse
 }
 
 // SendPointerEvent is used to send an PointerEvent to the Flutter engine.
 func (flu *FlutterEngine) SendPointerEvent(Event PointerEvent) Result {
 
 	cEvents := C.FlutterPointerEvent{
-		phase:     (C.FlutterPointerPhase)(Event.Phase),
-		x:         C.double(Event.X),
-		y:         C.double(Event.Y),
-		timestamp: C.size_t(Event.Timestamp),
+		phase:          (C.FlutterPointerPhase)(Event.Phase),
+		x:              C.double(Event.X),
+		y:              C.double(Event.Y),
+		timestamp:      C.size_t(Event.Timestamp),
+		device:         C.int32_t(Event.Device),
+		signal_kind:    (C.FlutterPointerSignalKind)(Event.SignalKind),
+		scroll_delta_x: C.double(Event.ScrollDeltaX),
+		scroll_delta_y: C.double(Event.ScrollDeltaY),
+		device_kind:    (C.FlutterPointerDeviceKind)(Event.DeviceKind),
+		buttons:        C.int64_t(Event.Buttons),
 	}
 	cEvents.struct_size = C.size_t(unsafe.Sizeof(cEvents))
 
@@ -171,35 +315,58 @@ func (flu *FlutterEngine) SendWindowMetricsEvent(Metric WindowMetricsEvent) Resu
 	return (Result)(res)
 }
 
-// PlatformMessage represents a `MethodChannel` serialized with the `JSONMethodCodec`
-// TODO Support for `StandardMethodCodec`
+// PlatformMessage represents a binary message sent over a platform channel.
+// Data holds the raw content of the message, it is up to the receiver to
+// decode it with the codec used by the channel.
 type PlatformMessage struct {
 	Channel        string
-	Message        Message
+	Data           []byte
 	ResponseHandle *C.FlutterPlatformMessageResponseHandle
 }
 
-// Message is the json content of a PlatformMessage
-type Message struct {
-	// Describe the method
-	Method string `json:"method"`
-	// Actual datas
-	Args json.RawMessage `json:"args"`
-}
-
 // SendPlatformMessage is used to send a PlatformMessage to the Flutter engine.
+// The Data of the message is sent as is.
 func (flu *FlutterEngine) SendPlatformMessage(Message *PlatformMessage) Result {
+	return flu.sendPlatformMessage(Message, nil)
+}
 
-	marshalled, err := json.Marshal(&Message.Message)
-	if err != nil {
-		panic("Cound not send a message to the flutter engine: Error while creating the JSON")
+// SendPlatformMessageWithReply is used to send a PlatformMessage to the
+// Flutter engine and receive the reply of the Flutter application.
+// The reply callback is called once, on the thread running the engine tasks,
+// with the raw content of the reply. The reply is empty when the Flutter
+// application has no handler for the channel, and nil when the engine is
+// shut down before replying.
+func (flu *FlutterEngine) SendPlatformMessageWithReply(Message *PlatformMessage, reply func(data []byte)) Result {
+	replyID := registerReply(flu, reply)
+
+	var responseHandle *C.FlutterPlatformMessageResponseHandle
+	res := C.createMessageResponseHandle(flu.Engine, C.uintptr_t(replyID), &responseHandle)
+	if (Result)(res) != KSuccess {
+		takeReply(replyID)
+		return (Result)(res)
 	}
-	strMessage := string(marshalled)
+	// The handle can be released as soon as the message is sent.
+	defer C.FlutterPlatformMessageReleaseResponseHandle(flu.Engine, responseHandle)
+
+	result := flu.sendPlatformMessage(Message, responseHandle)
+	if result != KSuccess {
+		takeReply(replyID)
+	}
+	return result
+}
+
+func (flu *FlutterEngine) sendPlatformMessage(Message *PlatformMessage, responseHandle *C.FlutterPlatformMessageResponseHandle) Result {
+
+	cChannel := C.CString(Message.Channel)
+	defer C.free(unsafe.Pointer(cChannel))
+	cData := C.CBytes(Message.Data)
+	defer C.free(cData)
 
 	cPlatformMessage := C.FlutterPlatformMessage{
-		channel:      C.CString(Message.Channel),
-		message:      (*C.uint8_t)(unsafe.Pointer(C.CString(strMessage))),
-		message_size: C.size_t(len(strMessage)),
+		channel:         cChannel,
+		message:         (*C.uint8_t)(cData),
+		message_size:    C.size_t(len(Message.Data)),
+		response_handle: responseHandle,
 	}
 
 	cPlatformMessage.struct_size = C.size_t(unsafe.Sizeof(cPlatformMessage))
@@ -212,22 +379,128 @@ func (flu *FlutterEngine) SendPlatformMessage(Message *PlatformMessage) Result {
 	return (Result)(res)
 }
 
+// Reply callbacks of the messages sent with SendPlatformMessageWithReply,
+// waiting for the Flutter application to answer. The key is passed to the
+// engine as the user data of the response handle.
+var (
+	pendingReplies     = make(map[uintptr]pendingReply)
+	pendingRepliesLock sync.Mutex
+	nextReplyID        uintptr = 1
+)
+
+// pendingReply is a reply callback and the engine the message was sent to.
+type pendingReply struct {
+	engine *FlutterEngine
+	reply  func(data []byte)
+}
+
+func registerReply(flu *FlutterEngine, reply func(data []byte)) uintptr {
+	pendingRepliesLock.Lock()
+	defer pendingRepliesLock.Unlock()
+	replyID := nextReplyID
+	nextReplyID++
+	pendingReplies[replyID] = pendingReply{engine: flu, reply: reply}
+	return replyID
+}
+
+// takeReply removes the reply callback from the pending replies and returns
+// it, nil is returned when the reply is unknown.
+func takeReply(replyID uintptr) func(data []byte) {
+	pendingRepliesLock.Lock()
+	defer pendingRepliesLock.Unlock()
+	pending := pendingReplies[replyID]
+	delete(pendingReplies, replyID)
+	return pending.reply
+}
+
+// cancelReplies removes the reply callbacks of the messages sent to the
+// engine and calls them with nil, the engine won't answer them anymore.
+func (flu *FlutterEngine) cancelReplies() {
+	var replies []func(data []byte)
+	pendingRepliesLock.Lock()
+	for replyID, pending := range pendingReplies {
+		if pending.engine == flu {
+			replies = append(replies, pending.reply)
+			delete(pendingReplies, replyID)
+		}
+	}
+	pendingRepliesLock.Unlock()
+	for _, reply := range replies {
+		reply(nil)
+	}
+}
+
 // SendPlatformMessageResponse is used to send a message to the Flutter side using the correct ResponseHandle!
 func (flu *FlutterEngine) SendPlatformMessageResponse(
 	responseTo *PlatformMessage,
 	data []byte,
 ) Result {
+	// The engine copies the data, the buffer is freed once the response is sent.
+	cData := C.CBytes(data)
+	defer C.free(cData)
 
 	res := C.FlutterEngineSendPlatformMessageResponse(
 		flu.Engine,
 		(*C.FlutterPlatformMessageResponseHandle)(responseTo.ResponseHandle),
-		(*C.uint8_t)(unsafe.Pointer(C.CBytes(data))),
+		(*C.uint8_t)(cData),
 		(C.size_t)(len(data)))
 
 	return (Result)(res)
 
 }
 
+// Transformation corresponds to the C.FlutterTransformation, a 3x3 matrix
+// applied to the coordinates of the surface:
+//
+//	x' = ScaleX*x + SkewX*y + TransX
+//	y' = SkewY*x + ScaleY*y + TransY
+//	w' = Pers0*x + Pers1*y + Pers2
+type Transformation struct {
+	ScaleX, SkewX, TransX float64
+	SkewY, ScaleY, TransY float64
+	Pers0, Pers1, Pers2   float64
+}
+
+// IdentityTransformation leaves the surface untouched.
+var IdentityTransformation = Transformation{
+	ScaleX: 1,
+	ScaleY: 1,
+	Pers2:  1,
+}
+
+// GLTexture describes the OpenGL texture of an external texture, see
+// FlutterEngine.FGLExternalTextureFrameCallback.
+type GLTexture struct {
+	// Target of the texture, e.g.: GL_TEXTURE_2D.
+	Target uint32
+	// Name of the texture.
+	Name uint32
+	// Format of the texture, e.g.: GL_RGBA8.
+	Format uint32
+}
+
+// RegisterExternalTexture registers an external texture, textureID is the
+// identifier given to the `Texture` widget of the dart side. External
+// textures are only supported by the KOpenGL renderer.
+func (flu *FlutterEngine) RegisterExternalTexture(textureID int64) Result {
+	res := C.FlutterEngineRegisterExternalTexture(flu.Engine, C.int64_t(textureID))
+	return (Result)(res)
+}
+
+// UnregisterExternalTexture unregisters an external texture.
+func (flu *FlutterEngine) UnregisterExternalTexture(textureID int64) Result {
+	res := C.FlutterEngineUnregisterExternalTexture(flu.Engine, C.int64_t(textureID))
+	return (Result)(res)
+}
+
+// MarkExternalTextureFrameAvailable tells the engine that a new frame of the
+// external texture is available, the engine then asks for the texture with
+// FGLExternalTextureFrameCallback.
+func (flu *FlutterEngine) MarkExternalTextureFrameAvailable(textureID int64) Result {
+	res := C.FlutterEngineMarkExternalTextureFrameAvailable(flu.Engine, C.int64_t(textureID))
+	return (Result)(res)
+}
+
 // FlutterEngineFlushPendingTasksNow flush tasks on a  message loop not controlled by the Flutter engine.
 // deprecated soon.
 func FlutterEngineFlushPendingTasksNow() {
@@ -1,6 +1,15 @@
 
 #include "library/flutter_embedder.h"
 #include <stdlib.h>
+#include <string.h>
+#ifdef _WIN32
+#include <windows.h>
+#else
+#include <fcntl.h>
+#include <sys/mman.h>
+#include <sys/stat.h>
+#include <unistd.h>
+#endif
 
 // C proxies def
 bool proxy_make_current(void *v);
@@ -9,29 +18,65 @@ bool proxy_present(void *v);
 uint32_t proxy_fbo_callback(void *v);
 bool proxy_make_resource_current(void *v);
 void *proxy_gl_proc_resolver(void *v, const char *procname);
-bool proxy_on_platform_message(FlutterPlatformMessage *message, void *window);
+bool proxy_gl_external_texture_frame_callback(void *userData, int64_t textureIdentifier, size_t width,
+                                              size_t height, FlutterOpenGLTexture *texture);
+FlutterTransformation proxy_surface_transformation(void *userData);
+bool proxy_software_surface_present(void *v, void *allocation, size_t row_bytes, size_t height);
+bool proxy_on_platform_message(FlutterPlatformMessage *message, void *userData);
+void proxy_platform_message_reply(uint8_t *data, size_t size, void *userData);
+
+// The GL textures given to the engine are owned by the embedder, they are
+// deleted when the texture is unregistered.
+static void noopTextureDestruction(void *userData) {}
+
+static bool glExternalTextureFrameCallback(void *userData, int64_t textureIdentifier, size_t width, size_t height,
+                                          FlutterOpenGLTexture *texture)
+{
+        texture->user_data = NULL;
+        texture->destruction_callback = noopTextureDestruction;
+        return proxy_gl_external_texture_frame_callback(userData, textureIdentifier, width, height, texture);
+}
 
 // C helper
-FlutterEngineResult runFlutter(uintptr_t window, FlutterEngine *engine, FlutterProjectArgs *Args,
-                               const char *const *vmArgs, int nVmAgrs)
+FlutterEngineResult runFlutter(uintptr_t userData, FlutterRendererType rendererType, bool fboResetAfterPresent,
+                               FlutterEngine *engine, FlutterProjectArgs *Args, const char *const *vmArgs,
+                               int nVmAgrs)
 {
 
         FlutterRendererConfig config = {};
-        config.type = kOpenGL;
+        config.type = rendererType;
 
-        config.open_gl.struct_size = sizeof(FlutterOpenGLRendererConfig);
-        config.open_gl.make_current = proxy_make_current;
-        config.open_gl.clear_current = proxy_clear_current;
-        config.open_gl.present = proxy_present;
-        config.open_gl.fbo_callback = proxy_fbo_callback;
-        config.open_gl.make_resource_current = proxy_make_resource_current;
-        config.open_gl.gl_proc_resolver = proxy_gl_proc_resolver;
+        if (rendererType == kSoftware)
+        {
+                config.software.struct_size = sizeof(FlutterSoftwareRendererConfig);
+                config.software.surface_present_callback = (SoftwareSurfacePresentCallback)proxy_software_surface_present;
+        }
+        else
+        {
+                config.open_gl.struct_size = sizeof(FlutterOpenGLRendererConfig);
+                config.open_gl.make_current = proxy_make_current;
+                config.open_gl.clear_current = proxy_clear_current;
+                config.open_gl.present = proxy_present;
+                config.open_gl.fbo_callback = proxy_fbo_callback;
+                config.open_gl.fbo_reset_after_present = fboResetAfterPresent;
+                config.open_gl.make_resource_current = proxy_make_resource_current;
+                config.open_gl.gl_proc_resolver = proxy_gl_proc_resolver;
+                config.open_gl.gl_external_texture_frame_callback = glExternalTextureFrameCallback;
+                config.open_gl.surface_transformation = proxy_surface_transformation;
+        }
 
         Args->command_line_argc = nVmAgrs;
         Args->command_line_argv = vmArgs;
         Args->platform_message_callback = (FlutterPlatformMessageCallback)proxy_on_platform_message;
 
-        return FlutterEngineRun(FLUTTER_ENGINE_VERSION, &config, Args, (void *)window, engine);
+        return FlutterEngineRun(FLUTTER_ENGINE_VERSION, &config, Args, (void *)userData, engine);
+}
+
+FlutterEngineResult createMessageResponseHandle(FlutterEngine engine, uintptr_t userData,
+                                               FlutterPlatformMessageResponseHandle **responseHandle)
+{
+        return FlutterPlatformMessageCreateResponseHandle(engine, (FlutterDataCallback)proxy_platform_message_reply,
+                                                          (void *)userData, responseHandle);
 }
 
 char **makeCharArray(int size)
@@ -43,3 +88,109 @@ void setArrayString(char **a, char *s, int n)
 {
         a[n] = s;
 }
+
+// mapSnapshotFile maps the file in memory, read-only or read-execute. It
+// returns NULL on failure.
+const uint8_t *mapSnapshotFile(const char *path, bool executable, size_t *size)
+{
+#ifdef _WIN32
+        HANDLE file = CreateFileA(path, GENERIC_READ | (executable ? GENERIC_EXECUTE : 0), FILE_SHARE_READ, NULL,
+                                  OPEN_EXISTING, FILE_ATTRIBUTE_NORMAL, NULL);
+        if (file == INVALID_HANDLE_VALUE)
+        {
+                return NULL;
+        }
+        LARGE_INTEGER fileSize;
+        if (!GetFileSizeEx(file, &fileSize) || fileSize.QuadPart == 0)
+        {
+                CloseHandle(file);
+                return NULL;
+        }
+        HANDLE mapping = CreateFileMappingA(file, NULL, executable ? PAGE_EXECUTE_READ : PAGE_READONLY, 0, 0, NULL);
+        CloseHandle(file);
+        if (mapping == NULL)
+        {
+                return NULL;
+        }
+        void *buffer = MapViewOfFile(mapping, FILE_MAP_READ | (executable ? FILE_MAP_EXECUTE : 0), 0, 0, 0);
+        CloseHandle(mapping);
+        if (buffer == NULL)
+        {
+                return NULL;
+        }
+        *size = (size_t)fileSize.QuadPart;
+        return buffer;
+#else
+        int fd = open(path, O_RDONLY);
+        if (fd < 0)
+        {
+                return NULL;
+        }
+        struct stat info;
+        if (fstat(fd, &info) != 0 || info.st_size == 0)
+        {
+                close(fd);
+                return NULL;
+        }
+        void *buffer = mmap(NULL, info.st_size, PROT_READ | (executable ? PROT_EXEC : 0), MAP_PRIVATE, fd, 0);
+        close(fd);
+        if (buffer == MAP_FAILED)
+        {
+                return NULL;
+        }
+        *size = info.st_size;
+        return buffer;
+#endif
+}
+
+// mapSnapshotBytes copies the bytes to new memory pages, read-only or
+// read-execute. It returns NULL on failure.
+const uint8_t *mapSnapshotBytes(const void *data, size_t size, bool executable)
+{
+#ifdef _WIN32
+        void *buffer = VirtualAlloc(NULL, size, MEM_COMMIT | MEM_RESERVE, PAGE_READWRITE);
+        if (buffer == NULL)
+        {
+                return NULL;
+        }
+        memcpy(buffer, data, size);
+        DWORD oldProtect;
+        if (!VirtualProtect(buffer, size, executable ? PAGE_EXECUTE_READ : PAGE_READONLY, &oldProtect))
+        {
+                VirtualFree(buffer, 0, MEM_RELEASE);
+                return NULL;
+        }
+        return buffer;
+#else
+        void *buffer = mmap(NULL, size, PROT_READ | PROT_WRITE, MAP_PRIVATE | MAP_ANONYMOUS, -1, 0);
+        if (buffer == MAP_FAILED)
+        {
+                return NULL;
+        }
+        memcpy(buffer, data, size);
+        if (mprotect(buffer, size, PROT_READ | (executable ? PROT_EXEC : 0)) != 0)
+        {
+                munmap(buffer, size);
+                return NULL;
+        }
+        return buffer;
+#endif
+}
+
+// unmapSnapshot releases a buffer mapped by mapSnapshotFile or
+// mapSnapshotBytes.
+void unmapSnapshot(const uint8_t *buffer, size_t size, bool fromFile)
+{
+#ifdef _WIN32
+        if (fromFile)
+        {
+                UnmapViewOfFile(buffer);
+        }
+        else
+        {
+                VirtualFree((void *)buffer, 0, MEM_RELEASE);
+        }
+#else
+        munmap((void *)buffer, size);
+#endif
+}
@@ -0,0 +1,116 @@
+package embedder
+
+// #include "flutter_embedder.h"
+import "C"
+import "unsafe"
+
+// C proxies
+//
+// The user data given to the engine by FlutterEngine.Run is the index of the
+// FlutterEngine, the proxies use it to call the callbacks of the right engine.
+
+// flutterEngineByUserData returns the FlutterEngine of the user data given to
+// the proxies by the engine.
+func flutterEngineByUserData(userData unsafe.Pointer) *FlutterEngine {
+	return FlutterEngineByIndex(int(uintptr(userData)))
+}
+
+//export proxy_on_platform_message
+func proxy_on_platform_message(message *C.FlutterPlatformMessage, userData unsafe.Pointer) C.bool {
+	FlutterPlatformMessage := &PlatformMessage{
+		Data:           C.GoBytes(unsafe.Pointer(message.message), C.int(message.message_size)),
+		Channel:        C.GoString(message.channel),
+		ResponseHandle: message.response_handle,
+	}
+	flutterEngine := flutterEngineByUserData(userData)
+	return C.bool(flutterEngine.FPlatfromMessage(FlutterPlatformMessage))
+}
+
+//export proxy_platform_message_reply
+func proxy_platform_message_reply(data *C.uint8_t, size C.size_t, userData unsafe.Pointer) {
+	reply := takeReply(uintptr(userData))
+	if reply != nil {
+		reply(C.GoBytes(unsafe.Pointer(data), C.int(size)))
+	}
+}
+
+//export proxy_make_current
+func proxy_make_current(userData unsafe.Pointer) C.bool {
+	flutterEngine := flutterEngineByUserData(userData)
+	return C.bool(flutterEngine.FMakeCurrent())
+}
+
+//export proxy_clear_current
+func proxy_clear_current(userData unsafe.Pointer) C.bool {
+	flutterEngine := flutterEngineByUserData(userData)
+	return C.bool(flutterEngine.FClearCurrent())
+}
+
+//export proxy_present
+func proxy_present(userData unsafe.Pointer) C.bool {
+	flutterEngine := flutterEngineByUserData(userData)
+	return C.bool(flutterEngine.FPresent())
+}
+
+//export proxy_fbo_callback
+func proxy_fbo_callback(userData unsafe.Pointer) C.uint32_t {
+	flutterEngine := flutterEngineByUserData(userData)
+	return C.uint32_t(flutterEngine.FFboCallback())
+}
+
+//export proxy_make_resource_current
+func proxy_make_resource_current(userData unsafe.Pointer) C.bool {
+	flutterEngine := flutterEngineByUserData(userData)
+	return C.bool(flutterEngine.FMakeResourceCurrent())
+}
+
+//export proxy_gl_proc_resolver
+func proxy_gl_proc_resolver(userData unsafe.Pointer, procname *C.char) unsafe.Pointer {
+	flutterEngine := flutterEngineByUserData(userData)
+	return flutterEngine.FGLProcResolver(C.GoString(procname))
+}
+
+//export proxy_gl_external_texture_frame_callback
+func proxy_gl_external_texture_frame_callback(userData unsafe.Pointer, textureIdentifier C.int64_t, width C.size_t, height C.size_t, texture *C.FlutterOpenGLTexture) C.bool {
+	flutterEngine := flutterEngineByUserData(userData)
+	if flutterEngine.FGLExternalTextureFrameCallback == nil {
+		return C.bool(false)
+	}
+	glTexture, ok := flutterEngine.FGLExternalTextureFrameCallback(int64(textureIdentifier), int(width), int(height))
+	if !ok {
+		return C.bool(false)
+	}
+	texture.target = C.uint32_t(glTexture.Target)
+	texture.name = C.uint32_t(glTexture.Name)
+	texture.format = C.uint32_t(glTexture.Format)
+	return C.bool(true)
+}
+
+//export proxy_surface_transformation
+func proxy_surface_transformation(userData unsafe.Pointer) C.FlutterTransformation {
+	flutterEngine := flutterEngineByUserData(userData)
+	transformation := IdentityTransformation
+	if flutterEngine.FSurfaceTransformation != nil {
+		transformation = flutterEngine.FSurfaceTransformation()
+	}
+	return C.FlutterTransformation{
+		scaleX: C.double(transformation.ScaleX),
+		skewX:  C.double(transformation.SkewX),
+		transX: C.double(transformation.TransX),
+		skewY:  C.double(transformation.SkewY),
+		scaleY: C.double(transformation.ScaleY),
+		transY: C.double(transformation.TransY),
+		pers0:  C.double(transformation.Pers0),
+		pers1:  C.double(transformation.Pers1),
+		pers2:  C.double(transformation.Pers2),
+	}
+}
+
+//export proxy_software_surface_present
+func proxy_software_surface_present(userData unsafe.Pointer, allocation unsafe.Pointer, rowBytes C.size_t, height C.size_t) C.bool {
+	flutterEngine := flutterEngineByUserData(userData)
+	// The allocation is owned by the engine, it is copied before the
+	// callback returns.
+	buffer := C.GoBytes(allocation, C.int(rowBytes*height))
+	return C.bool(flutterEngine.FSurfacePresent(buffer, int(rowBytes), int(height)))
+}
@@ -0,0 +1,27 @@
+package embedder
+
+import "testing"
+
+func TestCancelReplies(t *testing.T) {
+	engine, otherEngine := &FlutterEngine{}, &FlutterEngine{}
+
+	var replies [][]byte
+	replyID := registerReply(engine, func(data []byte) {
+		replies = append(replies, data)
+	})
+	otherReplyID := registerReply(otherEngine, func(data []byte) {
+		t.Fatal("reply of another engine cancelled")
+	})
+	defer takeReply(otherReplyID)
+
+	engine.cancelReplies()
+	if len(replies) != 1 || replies[0] != nil {
+		t.Fatalf("replies %v, expected a single nil reply", replies)
+	}
+	if takeReply(replyID) != nil {
+		t.Fatal("cancelled reply still pending")
+	}
+	if takeReply(otherReplyID) == nil {
+		t.Fatal("reply of another engine removed")
+	}
+}
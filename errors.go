@@ -0,0 +1,41 @@
+package flutter
+
+import (
+	"github.com/go-flutter-desktop/go-flutter/embedder"
+	"github.com/go-flutter-desktop/go-flutter/internal/embedding"
+)
+
+// The errors below are returned by Run when the application cannot start.
+// Use errors.Cause from github.com/pkg/errors and a type assertion to tell
+// them apart, for instance to show a dialog to the user. The headless
+// package returns the same types.
+
+// InvalidAssetsPathError is returned when the flutter assets directory is not
+// set or cannot be read. Path is the directory, Err the cause.
+type InvalidAssetsPathError = embedding.InvalidAssetsPathError
+
+// MissingICUDataError is returned when the ICU data file is not set or
+// cannot be read. Path is the file, Err the cause.
+type MissingICUDataError = embedding.MissingICUDataError
+
+// InvalidWindowDimensionError is returned when the initial window dimension
+// is not strictly positive.
+type InvalidWindowDimensionError = embedding.InvalidWindowDimensionError
+
+// EngineError is returned when the FlutterEngine refuses to start. Its Result
+// is embedder.KInvalidLibraryVersion when the engine library does not match
+// the version of the embedder API go-flutter is built with, and
+// embedder.KInvalidArguments when the engine rejects the project arguments.
+type EngineError = embedding.EngineError
+
+// IncompatibleOptionsError is returned when two options can't be used
+// together, e.g.: OptionSurfaceTransformation with OptionSoftwareRenderer.
+type IncompatibleOptionsError = embedding.IncompatibleOptionsError
+
+// AOTSnapshotError is returned when a buffer of the AOT snapshot can't be
+// loaded. Snapshot is the name of the buffer, Path its file, Err the cause.
+type AOTSnapshotError = embedder.AOTSnapshotError
+
+// InvalidOptionError is returned when an option is given an incomplete
+// value, e.g.: a SurfaceTransformation without Matrix.
+type InvalidOptionError = embedding.InvalidOptionError
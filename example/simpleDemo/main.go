@@ -5,7 +5,6 @@ import (
 	"fmt"
 	"image"
 	_ "image/png"
-	"log"
 	"os"
 	"path"
 	"runtime"
@@ -14,9 +13,7 @@ import (
 	"time"
 
 	"github.com/go-flutter-desktop/go-flutter"
-	"github.com/go-flutter-desktop/go-flutter/embedder"
-
-	"github.com/go-gl/glfw/v3.2/glfw"
+	"github.com/go-flutter-desktop/go-flutter/plugin"
 )
 
 func iconProvider() ([]image.Image, error) {
@@ -54,7 +51,7 @@ func main() {
 			"--observatory-port=50300",
 		}),
 
-		flutter.OptionAddPluginReceiver(ownPlugin, "plugin_demo"),
+		flutter.AddPlugin(&ownPlugin{}),
 
 		// Default keyboard is Qwerty, if you want to change it, you can check keyboard.go in gutter package.
 		// Otherwise you can create your own by usinng `KeyboardShortcuts` struct.
@@ -69,40 +66,38 @@ func main() {
 }
 
 // Plugin that read the stdin and send the number to the dart side
-func ownPlugin(
-	platMessage *embedder.PlatformMessage,
-	flutterEngine *embedder.FlutterEngine,
-	window *glfw.Window,
-) bool {
-
-	if platMessage.Message.Method != "getNumber" {
-		log.Printf("Unhandled platform method: %#v from channel %#v\n",
-			platMessage.Message.Method, platMessage.Channel)
-		return false
-	}
+type ownPlugin struct{}
 
-	time.Sleep(2 * time.Second)
+var _ flutter.Plugin = &ownPlugin{} // compile-time type check
+
+func (p *ownPlugin) InitPlugin(messenger plugin.BinaryMessenger) error {
+	channel := plugin.NewMethodChannel(messenger, "plugin_demo", plugin.JSONMethodCodec{})
+	channel.HandleFuncAsync("getNumber", p.handleGetNumber)
+	return nil
+}
+
+// handleGetNumber replies once a number has been read from the stdin, the
+// reply can be sent from any goroutine.
+func (p *ownPlugin) handleGetNumber(arguments interface{}, reply *plugin.MethodReply) {
 	go func() {
+		time.Sleep(2 * time.Second)
+		reader := bufio.NewReader(os.Stdin)
 		for {
 			fmt.Printf("Reading (A number): ")
-			reader := bufio.NewReader(os.Stdin)
 			s, err := reader.ReadString('\n')
 			if err != nil {
-				fmt.Printf("failed to read from stdin: %v", err)
+				reply.Error("io", fmt.Sprintf("failed to read from stdin: %v", err), nil)
 				return
 			}
 			s = strings.TrimRight(s, "\r\n")
-			_, err = strconv.Atoi(s)
+			number, err := strconv.Atoi(s)
 			if err != nil {
 				fmt.Printf("Failed to parse number: %v\n", err)
 				fmt.Println("Try again")
 				continue
 			}
-			flutterEngine.SendPlatformMessageResponse(platMessage, []byte("[ "+s+" ]"))
+			reply.Success(number)
 			return
 		}
 	}()
-
-	return true
-
 }
@@ -1,14 +1,15 @@
 package flutter
 
 import (
-	"encoding/json"
 	"fmt"
 	"log"
 	"runtime"
-	"time"
 	"unsafe"
 
 	"github.com/go-flutter-desktop/go-flutter/embedder"
+	"github.com/go-flutter-desktop/go-flutter/internal/embedding"
+	"github.com/go-flutter-desktop/go-flutter/internal/messenger"
+	"github.com/go-flutter-desktop/go-flutter/internal/systemchannels"
 	"github.com/go-gl/glfw/v3.2/glfw"
 	"github.com/pkg/errors"
 )
@@ -24,20 +25,51 @@ func Run(options ...Option) (err error) {
 		c      config
 	)
 
-	// The Windows Title Handler and the TextInput handler come by default
-	options = append(options, addHandlerWindowTitle())
-	options = append(options, addHandlerTextInput())
-	options = append(options, addHandlerClipboard())
+	// The Windows Title, Clipboard and the TextInput plugins come by default
+	options = append(options, AddPlugin(&platformPlugin{}))
+	options = append(options, AddPlugin(defaultTextinputPlugin))
+	options = append(options, AddPlugin(defaultKeyeventPlugin))
 
 	c = c.merge(options...)
 
+	err = c.ExtractBundle()
+	if err != nil {
+		return err
+	}
+	err = c.validate()
+	if err != nil {
+		return err
+	}
+
+	// Tasks posted from now on are executed by the main loop.
+	mainThreadTasks.Start()
+
+	// The initialized plugins are closed when Run returns, also on the error
+	// paths. Once the engine runs they are closed before it is shut down.
+	var initializedPlugins []Plugin
+	defer func() {
+		mainThreadTasks.Stop()
+		closePlugins(initializedPlugins)
+	}()
+
+	binaryMessenger := messenger.New(mainThreadTasks)
+	binaryMessenger.LogUnhandled = c.LogUnhandledMessages
+	binaryMessenger.PanicOnReplyMisuse = c.PanicOnReplyMisuse
+	for _, p := range c.Plugins {
+		err = p.InitPlugin(binaryMessenger)
+		if err != nil {
+			return errors.Wrapf(err, "failed to initialize plugin %T", p)
+		}
+		initializedPlugins = append(initializedPlugins, p)
+	}
+
 	err = glfw.Init()
 	if err != nil {
 		return errors.Wrap(err, "glfw init")
 	}
 	defer glfw.Terminate()
 
-	window, err = glfw.CreateWindow(c.WindowDimension.x, c.WindowDimension.y, "Loading..", nil, nil)
+	window, err = glfw.CreateWindow(c.Width, c.Height, "Loading..", nil, nil)
 	if err != nil {
 		return errors.Wrap(err, "creating glfw window")
 	}
@@ -58,63 +90,78 @@ func Run(options ...Option) (err error) {
 		}
 	}
 
-	flu := runFlutter(window, c)
+	// The hidden resource window shares its context with the window, the
+	// engine uses it to upload textures in the background.
+	var resourceWindow *glfw.Window
+	if !c.DisableResourceContext && !c.SoftwareRenderer {
+		glfw.WindowHint(glfw.Visible, glfw.False)
+		resourceWindow, err = glfw.CreateWindow(1, 1, "", nil, window)
+		glfw.DefaultWindowHints()
+		if err != nil {
+			log.Printf("failed to create the resource context, textures are uploaded by the render thread: %v\n", err)
+			resourceWindow = nil
+		} else {
+			defer resourceWindow.Destroy()
+		}
+	}
 
-	defer flu.Shutdown()
+	flu, textureRegistry, err := runFlutter(window, resourceWindow, c, binaryMessenger)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		mainThreadTasks.Stop()
+		closePlugins(initializedPlugins)
+		initializedPlugins = nil
+		flu.Shutdown()
+		flu.Release()
+		textureRegistry.release()
+	}()
+
+	for _, p := range c.Plugins {
+		// Extra init call for plugins that satisfy the PluginGLFW interface.
+		if glfwPlugin, ok := p.(PluginGLFW); ok {
+			err = glfwPlugin.InitPluginGLFW(window)
+			if err != nil {
+				return errors.Wrapf(err, "failed to initialize glfw plugin %T", p)
+			}
+		}
+		// Extra init call for plugins that satisfy the PluginWindow interface.
+		if windowPlugin, ok := p.(PluginWindow); ok {
+			err = windowPlugin.InitPluginWindow(window)
+			if err != nil {
+				return errors.Wrapf(err, "failed to initialize window plugin %T", p)
+			}
+		}
+		// Extra init call for plugins that satisfy the PluginTexture interface.
+		if texturePlugin, ok := p.(PluginTexture); ok {
+			err = texturePlugin.InitPluginTexture(textureRegistry)
+			if err != nil {
+				return errors.Wrapf(err, "failed to initialize texture plugin %T", p)
+			}
+		}
+	}
 
 	for !window.ShouldClose() {
 		// glfw.WaitEvents()
 		glfw.PollEvents()
+		defaultKeyeventPlugin.flushKeyEvent()
 		embedder.FlutterEngineFlushPendingTasksNow()
+		mainThreadTasks.Run()
 	}
 
 	return nil
 }
 
-// GLFW callbacks to the Flutter Engine
-func glfwCursorPositionCallbackAtPhase(
-	window *glfw.Window, phase embedder.PointerPhase,
-	x float64, y float64,
-) {
-	winWidth, _ := window.GetSize()
-	frameBuffWidth, _ := window.GetFramebufferSize()
-	contentScale := float64(frameBuffWidth / winWidth)
-	event := embedder.PointerEvent{
-		Phase:     phase,
-		X:         x * contentScale,
-		Y:         y * contentScale,
-		Timestamp: time.Now().UnixNano() / int64(time.Millisecond),
-	}
-
-	index := *(*int)(window.GetUserPointer())
-	flutterEngine := embedder.FlutterEngineByIndex(index)
-
-	flutterEngine.SendPointerEvent(event)
-}
-
-func glfwMouseButtonCallback(window *glfw.Window, key glfw.MouseButton, action glfw.Action, mods glfw.ModifierKey) {
-	if key == glfw.MouseButton1 {
-		x, y := window.GetCursorPos()
-
-		// recalculate x and y from screen cordinates to pixels
-		widthPx, _ := window.GetFramebufferSize()
-		width, _ := window.GetSize()
-		pixelsPerScreenCoordinate := float64(widthPx) / float64(width)
-		x = x * pixelsPerScreenCoordinate
-		y = y * pixelsPerScreenCoordinate
-
-		if action == glfw.Press {
-			glfwCursorPositionCallbackAtPhase(window, embedder.KDown, x, y)
-			window.SetCursorPosCallback(func(window *glfw.Window, x float64, y float64) {
-				x = x * pixelsPerScreenCoordinate
-				y = y * pixelsPerScreenCoordinate
-				glfwCursorPositionCallbackAtPhase(window, embedder.KMove, x, y)
-			})
-		}
-
-		if action == glfw.Release {
-			glfwCursorPositionCallbackAtPhase(window, embedder.KUp, x, y)
-			window.SetCursorPosCallback(nil)
+// closePlugins closes the plugins that satisfy the PluginCloser interface,
+// the errors are logged.
+func closePlugins(plugins []Plugin) {
+	for _, p := range plugins {
+		if closerPlugin, ok := p.(PluginCloser); ok {
+			err := closerPlugin.ClosePlugin()
+			if err != nil {
+				log.Printf("failed to close plugin %T: %v\n", p, err)
+			}
 		}
 	}
 }
@@ -157,6 +204,8 @@ func glfwKey(keyboardLayout KeyboardShortcuts) func(w *glfw.Window, key glfw.Key
 			modsIsShift = true
 		}
 
+		sendGLFWKeyEvent(key, scancode, action, mods)
+
 		if key == glfw.KeyEscape && action == glfw.Press {
 			w.SetShouldClose(true)
 		}
@@ -167,10 +216,10 @@ func glfwKey(keyboardLayout KeyboardShortcuts) func(w *glfw.Window, key glfw.Key
 				switch key {
 				case glfw.KeyEnter:
 					if mods == modifierKey {
-						performAction(w, "done")
+						defaultTextinputPlugin.performAction("done")
 					} else {
 						state.addChar([]rune{'\n'})
-						performAction(w, "newline")
+						defaultTextinputPlugin.performAction("newline")
 					}
 
 				case glfw.KeyHome:
@@ -226,16 +275,17 @@ func glfwKey(keyboardLayout KeyboardShortcuts) func(w *glfw.Window, key glfw.Key
 
 // newGLFWFramebufferSizeCallback creates a func that is called on framebuffer resizes.
 // When pixelRatio is set, the pixelRatio communicated to the Flutter embedder is not calculated.
-func newGLFWFramebufferSizeCallback(pixelRatio float64, monitorScreenCoordinatesPerInch float64) func(*glfw.Window, int, int) {
+// The size communicated to the Flutter embedder is the size of the transformed surface.
+func newGLFWFramebufferSizeCallback(pixelRatio float64, monitorScreenCoordinatesPerInch float64, transformer *surfaceTransformer, renderer *embedding.SoftwareRenderer) func(*glfw.Window, int, int) {
 	return func(window *glfw.Window, widthPx int, heightPx int) {
 		index := *(*int)(window.GetUserPointer())
 		flutterEngine := embedder.FlutterEngineByIndex(index)
 
 		// calculate pixelRatio when it has not been forced.
 		if pixelRatio == 0 {
-			width, _ := window.GetSize()
-			pixelsPerScreenCoordinate := float64(widthPx) / float64(width)
-			dpi := pixelsPerScreenCoordinate * monitorScreenCoordinatesPerInch
+			width, height := window.GetSize()
+			scale := newContentScale(width, height, widthPx, heightPx)
+			dpi := scale.x * monitorScreenCoordinatesPerInch
 			pixelRatio = dpi / dpPerInch
 
 			// Limit the ratio to 1 to avoid rendering a smaller UI in standard resolution monitors.
@@ -245,81 +295,161 @@ func newGLFWFramebufferSizeCallback(pixelRatio float64, monitorScreenCoordinates
 			}
 		}
 
+		transformer.resize(widthPx, heightPx)
+		surfaceWidth, surfaceHeight := transformer.surfaceSize(widthPx, heightPx)
+		if renderer != nil {
+			renderer.Resize(surfaceWidth)
+		}
+
 		event := embedder.WindowMetricsEvent{
-			Width:      widthPx,
-			Height:     heightPx,
+			Width:      surfaceWidth,
+			Height:     surfaceHeight,
 			PixelRatio: pixelRatio,
 		}
 		flutterEngine.SendWindowMetricsEvent(event)
 	}
 }
 
+// sendGLFWKeyEvent sends the raw key event, repeats are sent as keydown.
+func sendGLFWKeyEvent(key glfw.Key, scancode int, action glfw.Action, mods glfw.ModifierKey) {
+	eventType := systemchannels.KeyEventDown
+	if action == glfw.Release {
+		eventType = systemchannels.KeyEventUp
+	}
+	defaultKeyeventPlugin.handleKeyEvent(eventType, int(key), scancode, int(mods))
+}
+
 func glfwCharCallback(w *glfw.Window, char rune) {
+	defaultKeyeventPlugin.handleChar(char)
 	if state.clientID != 0 {
 		state.addChar([]rune{char})
 	}
 }
 
+// bufferSwapper is the part of the glfw window presenting the frames.
+type bufferSwapper interface {
+	SwapBuffers()
+}
+
+// newPresentCallback returns the present callback of the engine. With an
+// FBOTarget the frames are rendered into the FBO of the host, which presents
+// them in PostPresent: the buffers of the window aren't swapped.
+func newPresentCallback(window bufferSwapper, target *FBOTarget) func() bool {
+	if target != nil {
+		return func() bool {
+			if target.PostPresent != nil {
+				target.PostPresent()
+			}
+			return true
+		}
+	}
+	return func() bool {
+		window.SwapBuffers()
+		return true
+	}
+}
+
 // Flutter Engine
-func runFlutter(window *glfw.Window, c config) *embedder.FlutterEngine {
+func runFlutter(window *glfw.Window, resourceWindow *glfw.Window, c config, binaryMessenger *messenger.Messenger) (*embedder.FlutterEngine, *TextureRegistry, error) {
 	flutterEngine := embedder.NewFlutterEngine()
+	binaryMessenger.Engine = flutterEngine
+	textureRegistry := newTextureRegistry(flutterEngine, mainThreadTasks, glfw.GetProcAddress, c.SoftwareRenderer)
 
 	// Engine arguments
 	flutterEngine.AssetsPath = c.AssetsPath
 	flutterEngine.IcuDataPath = c.ICUDataPath
+	flutterEngine.AOTSnapshot = c.AOTSnapshot
 
 	// Render callbacks
-	flutterEngine.FMakeCurrent = func(v unsafe.Pointer) bool {
-		w := glfw.GoWindow(v)
-		w.MakeContextCurrent()
+	flutterEngine.FMakeCurrent = func() bool {
+		window.MakeContextCurrent()
 		return true
 	}
-	flutterEngine.FClearCurrent = func(v unsafe.Pointer) bool {
+	flutterEngine.FClearCurrent = func() bool {
 		glfw.DetachCurrentContext()
 		return true
 	}
-	flutterEngine.FPresent = func(v unsafe.Pointer) bool {
-		w := glfw.GoWindow(v)
-		w.SwapBuffers()
+	flutterEngine.FPresent = newPresentCallback(window, c.FBOTarget)
+	flutterEngine.FFboCallback = func() uint32 {
+		if c.FBOTarget != nil && c.FBOTarget.FBO != nil {
+			return c.FBOTarget.FBO()
+		}
+		return 0
+	}
+	if c.FBOTarget != nil {
+		flutterEngine.FBOResetAfterPresent = c.FBOTarget.ResetAfterPresent
+	}
+	flutterEngine.FMakeResourceCurrent = func() bool {
+		if resourceWindow == nil {
+			return false
+		}
+		resourceWindow.MakeContextCurrent()
 		return true
 	}
-	flutterEngine.FFboCallback = func(v unsafe.Pointer) int32 {
-		return 0
+	flutterEngine.FGLProcResolver = func(procName string) unsafe.Pointer {
+		return glfw.GetProcAddress(procName)
 	}
-	flutterEngine.FMakeResourceCurrent = func(v unsafe.Pointer) bool {
-		return false
+	flutterEngine.FGLExternalTextureFrameCallback = textureRegistry.handleFrameCallback
+	transformer := newSurfaceTransformer(c.SurfaceTransformation)
+	if transformer != nil {
+		flutterEngine.FSurfaceTransformation = transformer.current
+	}
+	var renderer *embedding.SoftwareRenderer
+	if c.SoftwareRenderer {
+		renderer = newSoftwareRenderer(window, c.SoftwareFrameCallback)
+		flutterEngine.RendererType = embedder.KSoftware
+		flutterEngine.FSurfacePresent = renderer.Present
 	}
 
 	// PlatformMessage
-	flutterEngine.FPlatfromMessage = func(platMessage *embedder.PlatformMessage, window unsafe.Pointer) bool {
-		windows := glfw.GoWindow(window)
-
+	flutterEngine.FPlatfromMessage = func(platMessage *embedder.PlatformMessage) bool {
 		hasDispatched := false
 
 		// Dispatch the message from the Flutter Engine, to all of the PluginReceivers
 		// having the same embedder.PlatformMessage.Channel name
 		for _, receivers := range c.PlatformMessageReceivers[platMessage.Channel] {
-			hasDispatched = receivers(platMessage, flutterEngine, windows) || hasDispatched
+			hasDispatched = receivers(platMessage, flutterEngine, window) || hasDispatched
 		}
 
-		return hasDispatched
-	}
+		// Dispatch the message to the channel handler registered on the
+		// messenger by the plugins, unless a deprecated receiver has already
+		// handled it.
+		if !hasDispatched {
+			hasDispatched = binaryMessenger.HandlePlatformMessage(platMessage)
+		}
 
-	state.notifyState = func() {
-		// log.Printf("Text: Sending to the flutter engine %v", state)
-		updateEditingState(window)
+		// Always reply to the messages nobody has claimed, otherwise the
+		// response handle leaks and the future on the dart side never
+		// completes.
+		if !hasDispatched {
+			binaryMessenger.HandleUnclaimedMessage(platMessage)
+		}
+
+		return hasDispatched
 	}
 
 	flutterEngineIndex := flutterEngine.Index()
 	window.SetUserPointer(unsafe.Pointer(&flutterEngineIndex))
-	result := flutterEngine.Run(window.GLFWWindow(), c.VMArguments)
+	err := flutterEngine.LoadAOTSnapshot()
+	if err != nil {
+		flutterEngine.Release()
+		textureRegistry.release()
+		return nil, nil, err
+	}
+	result := flutterEngine.Run(c.VMArguments)
 
 	if result != embedder.KSuccess {
-		window.Destroy()
-		panic("Couldn't launch the FlutterEngine")
+		flutterEngine.Release()
+		textureRegistry.release()
+		return nil, nil, &EngineError{
+			Result:      result,
+			AssetsPath:  c.AssetsPath,
+			ICUDataPath: c.ICUDataPath,
+			VMArguments: c.VMArguments,
+		}
 	}
 
-	glfwFramebufferSizeCallback := newGLFWFramebufferSizeCallback(c.ForcePixelRatio, getScreenCoordinatesPerInch())
+	glfwFramebufferSizeCallback := newGLFWFramebufferSizeCallback(c.ForcePixelRatio, getScreenCoordinatesPerInch(), transformer, renderer)
 	width, height := window.GetFramebufferSize()
 	glfwFramebufferSizeCallback(window, width, height)
 	var glfwKeyCallback func(w *glfw.Window, key glfw.Key, scancode int, action glfw.Action, mods glfw.ModifierKey)
@@ -332,9 +462,14 @@ func runFlutter(window *glfw.Window, c config) *embedder.FlutterEngine {
 
 	window.SetKeyCallback(glfwKeyCallback)
 	window.SetFramebufferSizeCallback(glfwFramebufferSizeCallback)
-	window.SetMouseButtonCallback(glfwMouseButtonCallback)
+	pointer := newWindowPointer(flutterEngine, transformer, c.ScrollAmount)
+	window.SetMouseButtonCallback(pointer.glfwMouseButtonCallback)
+	window.SetCursorPosCallback(pointer.glfwCursorPosCallback)
+	window.SetScrollCallback(pointer.glfwScrollCallback)
+	window.SetCursorEnterCallback(pointer.glfwCursorEnterCallback)
+	window.SetFocusCallback(pointer.glfwFocusCallback)
 	window.SetCharCallback(glfwCharCallback)
-	return flutterEngine
+	return flutterEngine, textureRegistry, nil
 }
 
 // getScreenCoordinatesPerInch returns the number of screen coordinates per
@@ -356,54 +491,3 @@ func getScreenCoordinatesPerInch() float64 {
 	}
 	return float64(primaryMonitorMode.Width) / (float64(primaryMonitorWidthMM) / 25.4)
 }
-
-// Update the TextInput with the current state
-func updateEditingState(window *glfw.Window) {
-	editingState := argsEditingState{
-		Text:                   string(state.word),
-		SelectionAffinity:      "TextAffinity.downstream",
-		SelectionBase:          state.selectionBase,
-		SelectionExtent:        state.selectionExtent,
-		SelectionIsDirectional: false,
-	}
-
-	editingStateMarchalled, _ := json.Marshal([]interface{}{
-		state.clientID,
-		editingState,
-	})
-
-	message := embedder.Message{
-		Args:   editingStateMarchalled,
-		Method: textUpdateStateMethod,
-	}
-
-	var mess = &embedder.PlatformMessage{
-		Channel: textInputChannel,
-		Message: message,
-	}
-
-	index := *(*int)(window.GetUserPointer())
-	flutterEngine := embedder.FlutterEngineByIndex(index)
-
-	flutterEngine.SendPlatformMessage(mess)
-}
-
-func performAction(window *glfw.Window, action string) {
-	actionArgs, _ := json.Marshal([]interface{}{
-		state.clientID,
-		"TextInputAction." + action,
-	})
-	message := embedder.Message{
-		Args:   actionArgs,
-		Method: "TextInputClient.performAction",
-	}
-	var mess = &embedder.PlatformMessage{
-		Channel: textInputChannel,
-		Message: message,
-	}
-
-	index := *(*int)(window.GetUserPointer())
-	flutterEngine := embedder.FlutterEngineByIndex(index)
-
-	flutterEngine.SendPlatformMessage(mess)
-}
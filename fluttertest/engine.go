@@ -0,0 +1,177 @@
+// Package fluttertest runs a flutter application without window to write
+// integration tests with `go test`.
+//
+// The application is driven from the test: pointer, keyboard and text input
+// events are injected, frames are awaited and inspected, and every platform
+// message exchanged with the dart side is recorded.
+//
+//	engine, err := fluttertest.Start(fluttertest.Config{
+//		AssetsPath:  "build/flutter_assets",
+//		ICUDataPath: "icudtl.dat",
+//		Width:       800,
+//		Height:      600,
+//	})
+//	if err != nil {
+//		t.Fatal(err)
+//	}
+//	defer engine.Shutdown()
+//	engine.Tap(100, 100)
+//	frame, err := engine.WaitForFrame(ctx)
+//
+// The application runs on top of the headless package, the plugins of the
+// flutter package are not added: the messages of the dart side are answered
+// by the handlers of HandleChannel.
+package fluttertest
+
+import (
+	"context"
+	"image"
+	"sync"
+
+	"github.com/go-flutter-desktop/go-flutter/embedder"
+	"github.com/go-flutter-desktop/go-flutter/headless"
+	"github.com/go-flutter-desktop/go-flutter/plugin"
+	"github.com/pkg/errors"
+)
+
+// Config holds the settings of the application under test.
+type Config struct {
+	// AssetsPath is the flutter_assets directory of the application.
+	AssetsPath string
+	// ICUDataPath is the path to the icudtl.dat file.
+	ICUDataPath string
+	// Width and Height are the initial size of the window, in physical
+	// pixels.
+	Width  int
+	Height int
+	// PixelRatio is the initial pixel ratio of the window, 1.0 when zero.
+	PixelRatio float64
+	// VMArguments are passed to the Dart VM.
+	VMArguments []string
+}
+
+// Engine is a flutter application running headless, with the software
+// renderer. Its methods are safe for concurrent use.
+type Engine struct {
+	app       *headless.Application
+	messenger orderedMessenger
+	// sendPointerEvent sends the pointer events of Tap,
+	// headless.Application.SendPointerEvent outside of the tests.
+	sendPointerEvent func(event embedder.PointerEvent) error
+
+	// ctx is done once the Engine is shut down, it ends the goroutines
+	// waiting for replies.
+	ctx    context.Context
+	cancel context.CancelFunc
+
+	frameLock    sync.Mutex
+	lastFrame    *image.RGBA
+	frameWaiters []chan *image.RGBA
+
+	messagesLock sync.Mutex
+	messages     []*Message
+	handlers     map[string]HandlerFunc
+
+	textInput textInput
+}
+
+// Start runs the application. It returns once the engine is running, the
+// application runs until Shutdown is called. Start returns the errors of
+// headless.Run.
+func Start(config Config) (*Engine, error) {
+	e := &Engine{
+		handlers: make(map[string]HandlerFunc),
+	}
+	e.ctx, e.cancel = context.WithCancel(context.Background())
+	e.textInput.engine = e
+
+	options := []headless.Option{
+		headless.ProjectAssetsPath(config.AssetsPath),
+		headless.ApplicationICUDataPath(config.ICUDataPath),
+		headless.ApplicationWindowDimension(config.Width, config.Height),
+		headless.OptionPixelRatio(config.PixelRatio),
+		headless.OptionFrameCallback(e.handleFrame),
+		headless.OptionUnclaimedMessageHandler(e.handlePlatformMessage),
+		headless.AddPlugin(messengerPlugin{engine: e}),
+	}
+	if config.VMArguments != nil {
+		options = append(options, headless.OptionVMArguments(config.VMArguments))
+	}
+	app, err := headless.Run(options...)
+	if err != nil {
+		e.cancel()
+		return nil, err
+	}
+	e.app = app
+	e.sendPointerEvent = app.SendPointerEvent
+	return e, nil
+}
+
+// orderedMessenger is the messenger of the headless applications. It sends
+// the messages in the order of the calls without waiting for their reply.
+type orderedMessenger interface {
+	plugin.BinaryMessenger
+	SendWithReplyHandler(channel string, binaryMessage []byte, replyHandler func(binaryReply []byte)) error
+}
+
+// messengerPlugin gives the messenger of the application to the Engine.
+type messengerPlugin struct {
+	engine *Engine
+}
+
+// InitPlugin satisfies headless.Plugin
+func (p messengerPlugin) InitPlugin(messenger plugin.BinaryMessenger) error {
+	m, ok := messenger.(orderedMessenger)
+	if !ok {
+		return errors.Errorf("unsupported messenger %T", messenger)
+	}
+	p.engine.messenger = m
+	return nil
+}
+
+// Shutdown stops the application and shuts the engine down. It must be
+// called once.
+func (e *Engine) Shutdown() {
+	e.cancel()
+	e.app.Shutdown()
+}
+
+// handleFrame keeps the frame and hands it to the goroutines waiting for
+// it. It is called by the render thread of the engine.
+func (e *Engine) handleFrame(frame *image.RGBA) {
+	e.frameLock.Lock()
+	e.lastFrame = frame
+	waiters := e.frameWaiters
+	e.frameWaiters = nil
+	e.frameLock.Unlock()
+
+	for _, waiter := range waiters {
+		waiter <- frame
+	}
+}
+
+// WaitForFrame waits for the next frame presented by the engine, or for the
+// context to be done.
+func (e *Engine) WaitForFrame(ctx context.Context) (*image.RGBA, error) {
+	waiter := make(chan *image.RGBA, 1)
+	e.frameLock.Lock()
+	e.frameWaiters = append(e.frameWaiters, waiter)
+	e.frameLock.Unlock()
+
+	select {
+	case frame := <-waiter:
+		return frame, nil
+	case <-ctx.Done():
+		return nil, errors.Wrap(ctx.Err(), "waiting for a frame")
+	case <-e.ctx.Done():
+		return nil, errors.New("waiting for a frame: the engine is shut down")
+	}
+}
+
+// LastFrame returns the last frame presented by the engine, nil when no
+// frame has been presented yet.
+func (e *Engine) LastFrame() *image.RGBA {
+	e.frameLock.Lock()
+	defer e.frameLock.Unlock()
+	return e.lastFrame
+}
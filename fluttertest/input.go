@@ -0,0 +1,161 @@
+package fluttertest
+
+import (
+	"encoding/json"
+	"sync"
+	"time"
+
+	"github.com/go-flutter-desktop/go-flutter/embedder"
+	"github.com/go-flutter-desktop/go-flutter/internal/systemchannels"
+	"github.com/go-flutter-desktop/go-flutter/plugin"
+	"github.com/pkg/errors"
+)
+
+// Resize sends new window metrics to the application, width and height are
+// in physical pixels.
+func (e *Engine) Resize(width int, height int, pixelRatio float64) error {
+	return e.app.Resize(width, height, pixelRatio)
+}
+
+// pointerDevice is the device of the pointer events sent by Tap, the glfw
+// embedder uses the same device for its mouse.
+const pointerDevice = 1
+
+// Tap clicks at x, y, in physical pixels, with the primary mouse button. The
+// mouse is added at x, y, pressed, released and removed, like the events
+// sent by the glfw embedder when the cursor enters the window, clicks and
+// leaves.
+func (e *Engine) Tap(x float64, y float64) error {
+	events := []struct {
+		phase   embedder.PointerPhase
+		buttons embedder.PointerButtonMouse
+	}{
+		{embedder.KAdd, 0},
+		{embedder.KDown, embedder.PointerButtonMousePrimary},
+		{embedder.KUp, 0},
+		{embedder.KRemove, 0},
+	}
+	for _, event := range events {
+		err := e.sendPointerEvent(embedder.PointerEvent{
+			Phase:      event.phase,
+			Timestamp:  time.Now().UnixNano() / int64(time.Microsecond),
+			X:          x,
+			Y:          y,
+			Device:     pointerDevice,
+			DeviceKind: embedder.PointerDeviceKindMouse,
+			Buttons:    event.buttons,
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// SendKey sends a keydown followed by a keyup raw key event, as the glfw
+// embedder does: keyCode, scanCode and modifiers are glfw values, char is
+// the character produced by the key, 0 when none.
+func (e *Engine) SendKey(keyCode int, scanCode int, modifiers int, char rune) error {
+	event := systemchannels.KeyEvent{
+		KeyCode:             keyCode,
+		ScanCode:            scanCode,
+		Modifiers:           modifiers,
+		UnicodeScalarValues: char,
+	}
+	for _, eventType := range []string{systemchannels.KeyEventDown, systemchannels.KeyEventUp} {
+		event.Type = eventType
+		data, err := systemchannels.EncodeKeyEvent(event)
+		if err != nil {
+			return errors.Wrap(err, "failed to encode key event")
+		}
+		err = e.Send(systemchannels.KeyEventChannel, data)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// TypeText types text in the focused text field, replacing its selection.
+// It returns an error when no text field has the focus.
+func (e *Engine) TypeText(text string) error {
+	return e.textInput.typeText(text)
+}
+
+// textInput follows the text field having the focus from the textinput
+// method calls of the dart side.
+type textInput struct {
+	engine *Engine
+
+	lock     sync.Mutex
+	clientID float64
+	state    systemchannels.EditingState
+}
+
+// observe updates the client and its state from a message of the dart side.
+func (t *textInput) observe(channel string, data []byte) {
+	if channel != systemchannels.TextInputChannel {
+		return
+	}
+	methodCall, err := plugin.JSONMethodCodec{}.DecodeMethodCall(data)
+	if err != nil {
+		return
+	}
+	arguments, _ := methodCall.Arguments.(json.RawMessage)
+
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	switch methodCall.Method {
+	case systemchannels.TextInputClientSet:
+		var args []json.RawMessage
+		if json.Unmarshal(arguments, &args) == nil && len(args) > 0 {
+			json.Unmarshal(args[0], &t.clientID)
+			t.state = systemchannels.EditingState{}
+		}
+	case systemchannels.TextInputClientClear:
+		t.clientID = 0
+	case systemchannels.TextInputSetEditState:
+		json.Unmarshal(arguments, &t.state)
+	}
+}
+
+func (t *textInput) typeText(text string) error {
+	t.lock.Lock()
+	if t.clientID == 0 {
+		t.lock.Unlock()
+		return errors.New("failed to type text: no text field has the focus")
+	}
+	word := []rune(t.state.Text)
+	start, end := t.state.SelectionBase, t.state.SelectionExtent
+	if start > end {
+		start, end = end, start
+	}
+	if start < 0 || end > len(word) {
+		start, end = len(word), len(word)
+	}
+	typed := []rune(text)
+	newWord := make([]rune, 0, len(word)-(end-start)+len(typed))
+	newWord = append(newWord, word[:start]...)
+	newWord = append(newWord, typed...)
+	newWord = append(newWord, word[end:]...)
+
+	t.state = systemchannels.EditingState{
+		Text:              string(newWord),
+		SelectionBase:     start + len(typed),
+		SelectionExtent:   start + len(typed),
+		SelectionAffinity: "TextAffinity.downstream",
+		ComposingBase:     -1,
+		ComposingExtent:   -1,
+	}
+	arguments := []interface{}{t.clientID, t.state}
+	t.lock.Unlock()
+
+	data, err := plugin.JSONMethodCodec{}.EncodeMethodCall(plugin.MethodCall{
+		Method:    systemchannels.TextUpdateStateMethod,
+		Arguments: arguments,
+	})
+	if err != nil {
+		return errors.Wrap(err, "failed to encode the editing state")
+	}
+	return t.engine.Send(systemchannels.TextInputChannel, data)
+}
@@ -0,0 +1,165 @@
+package fluttertest
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/go-flutter-desktop/go-flutter/embedder"
+	"github.com/go-flutter-desktop/go-flutter/internal/systemchannels"
+	"github.com/go-flutter-desktop/go-flutter/plugin"
+	"github.com/go-flutter-desktop/go-flutter/plugin/plugintest"
+)
+
+// fakeMessenger records the messages sent by the Engine, the replies are
+// never received.
+type fakeMessenger struct {
+	*plugintest.Messenger
+}
+
+func (m fakeMessenger) SendWithReplyHandler(channel string, binaryMessage []byte, replyHandler func(binaryReply []byte)) error {
+	return m.Send(channel, binaryMessage)
+}
+
+// testEngine returns an Engine sending its messages to messenger and
+// recording its pointer events, without application.
+func testEngine(messenger *plugintest.Messenger, events *[]embedder.PointerEvent) *Engine {
+	e := &Engine{
+		messenger: fakeMessenger{messenger},
+		sendPointerEvent: func(event embedder.PointerEvent) error {
+			*events = append(*events, event)
+			return nil
+		},
+		handlers: make(map[string]HandlerFunc),
+	}
+	e.ctx, e.cancel = context.WithCancel(context.Background())
+	e.textInput.engine = e
+	return e
+}
+
+func TestTap(t *testing.T) {
+	var events []embedder.PointerEvent
+	e := testEngine(plugintest.NewMessenger(), &events)
+	err := e.Tap(100, 40)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []struct {
+		phase   embedder.PointerPhase
+		buttons embedder.PointerButtonMouse
+	}{
+		{embedder.KAdd, 0},
+		{embedder.KDown, embedder.PointerButtonMousePrimary},
+		{embedder.KUp, 0},
+		{embedder.KRemove, 0},
+	}
+	if len(events) != len(expected) {
+		t.Fatalf("%d events sent, expected %d: %+v", len(events), len(expected), events)
+	}
+	for i, event := range events {
+		if event.Phase != expected[i].phase || event.Buttons != expected[i].buttons {
+			t.Errorf("event %d: phase %d buttons %d, expected phase %d buttons %d",
+				i, event.Phase, event.Buttons, expected[i].phase, expected[i].buttons)
+		}
+		if event.X != 100 || event.Y != 40 {
+			t.Errorf("event %d at (%v, %v), expected (100, 40)", i, event.X, event.Y)
+		}
+		if event.Device != pointerDevice || event.DeviceKind != embedder.PointerDeviceKindMouse {
+			t.Errorf("event %d: device %d kind %d, expected a mouse", i, event.Device, event.DeviceKind)
+		}
+	}
+}
+
+func TestSendKey(t *testing.T) {
+	messenger := plugintest.NewMessenger()
+	var events []embedder.PointerEvent
+	e := testEngine(messenger, &events)
+	// shift+a
+	err := e.SendKey(65, 38, 1, 'A')
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	sent := messenger.Sent()
+	if len(sent) != 2 {
+		t.Fatalf("%d key events sent, expected 2", len(sent))
+	}
+	for i, eventType := range []string{systemchannels.KeyEventDown, systemchannels.KeyEventUp} {
+		if sent[i].Channel != systemchannels.KeyEventChannel {
+			t.Fatalf("key event sent on %s, expected %s", sent[i].Channel, systemchannels.KeyEventChannel)
+		}
+		var event map[string]interface{}
+		err = json.Unmarshal(sent[i].Data, &event)
+		if err != nil {
+			t.Fatal(err)
+		}
+		expected := map[string]interface{}{
+			"type":                eventType,
+			"keymap":              "linux",
+			"toolkit":             "glfw",
+			"keyCode":             65.0,
+			"scanCode":            38.0,
+			"modifiers":           1.0,
+			"unicodeScalarValues": float64('A'),
+		}
+		if len(event) != len(expected) {
+			t.Errorf("key event %d: %v, expected %v", i, event, expected)
+		}
+		for key, value := range expected {
+			if event[key] != value {
+				t.Errorf("key event %d: %s is %v, expected %v", i, key, event[key], value)
+			}
+		}
+	}
+}
+
+func TestTypeText(t *testing.T) {
+	messenger := plugintest.NewMessenger()
+	var events []embedder.PointerEvent
+	e := testEngine(messenger, &events)
+	err := e.TypeText("a")
+	if err == nil {
+		t.Fatal("text typed without focused text field")
+	}
+
+	codec := plugin.JSONMethodCodec{}
+	for _, methodCall := range []plugin.MethodCall{
+		{Method: systemchannels.TextInputClientSet, Arguments: []interface{}{3, map[string]interface{}{}}},
+		{Method: systemchannels.TextInputSetEditState, Arguments: systemchannels.EditingState{Text: "hllo", SelectionBase: 1, SelectionExtent: 1}},
+	} {
+		data, err := codec.EncodeMethodCall(methodCall)
+		if err != nil {
+			t.Fatal(err)
+		}
+		e.textInput.observe(systemchannels.TextInputChannel, data)
+	}
+	err = e.TypeText("e")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	sent := messenger.Sent()
+	if len(sent) != 1 || sent[0].Channel != systemchannels.TextInputChannel {
+		t.Fatalf("sent %+v, expected an editing state update", sent)
+	}
+	methodCall, err := codec.DecodeMethodCall(sent[0].Data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if methodCall.Method != systemchannels.TextUpdateStateMethod {
+		t.Fatalf("method %s called, expected %s", methodCall.Method, systemchannels.TextUpdateStateMethod)
+	}
+	var arguments []json.RawMessage
+	err = json.Unmarshal(methodCall.Arguments.(json.RawMessage), &arguments)
+	if err != nil || len(arguments) != 2 {
+		t.Fatalf("invalid arguments %s: %v", methodCall.Arguments, err)
+	}
+	var clientID float64
+	var state systemchannels.EditingState
+	json.Unmarshal(arguments[0], &clientID)
+	json.Unmarshal(arguments[1], &state)
+	if clientID != 3 || state.Text != "hello" || state.SelectionBase != 2 || state.SelectionExtent != 2 {
+		t.Fatalf("client %v state %+v, expected client 3 with \"hello\" selected at 2", clientID, state)
+	}
+}
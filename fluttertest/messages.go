@@ -0,0 +1,127 @@
+package fluttertest
+
+import (
+	"context"
+
+	"github.com/go-flutter-desktop/go-flutter/plugin"
+	"github.com/pkg/errors"
+)
+
+// Direction tells which side has sent a platform message.
+type Direction int
+
+// Values representing the direction of a message.
+const (
+	// FromFlutter messages are sent by the dart side to the host.
+	FromFlutter Direction = iota
+	// ToFlutter messages are sent by the host to the dart side.
+	ToFlutter
+)
+
+// Message is a platform message recorded by the Engine, encoded with the
+// codec of its channel.
+type Message struct {
+	Direction Direction
+	Channel   string
+	Data      []byte
+	// Reply holds the reply to the message once Replied is true. An empty
+	// reply is received as null, or as not implemented for method calls.
+	Reply   []byte
+	Replied bool
+}
+
+// HandlerFunc answers the messages sent by the dart side on a channel. The
+// returned reply is sent back as is.
+type HandlerFunc func(message []byte) (reply []byte)
+
+// HandleChannel registers a handler for the messages sent by the dart side on
+// channel, a nil handler removes it. Messages without handler are answered
+// with an empty reply. Handlers are called by the platform thread and must
+// not call the methods of the Engine.
+func (e *Engine) HandleChannel(channel string, handler HandlerFunc) {
+	e.messagesLock.Lock()
+	if handler == nil {
+		delete(e.handlers, channel)
+	} else {
+		e.handlers[channel] = handler
+	}
+	e.messagesLock.Unlock()
+}
+
+// Messages returns the platform messages exchanged with the dart side so
+// far, in order.
+func (e *Engine) Messages() []Message {
+	e.messagesLock.Lock()
+	defer e.messagesLock.Unlock()
+	messages := make([]Message, len(e.messages))
+	for i, message := range e.messages {
+		messages[i] = *message
+	}
+	return messages
+}
+
+// Send sends a message to the dart side without waiting for the reply, which
+// is recorded once received. The messages are received by the dart side in
+// the order of the calls. Failures of the engine are logged.
+func (e *Engine) Send(channel string, data []byte) error {
+	if e.ctx.Err() != nil {
+		return errors.Errorf("failed to send message on channel %s: the engine is shut down", channel)
+	}
+	record := e.record(ToFlutter, channel, data)
+	return e.messenger.SendWithReplyHandler(channel, data, func(reply []byte) {
+		// nil when the engine is shut down before replying
+		if reply != nil {
+			e.recordReply(record, reply)
+		}
+	})
+}
+
+// SendWithReply sends a message to the dart side and waits for its reply, or
+// for the context to be done.
+func (e *Engine) SendWithReply(ctx context.Context, channel string, data []byte) (reply []byte, err error) {
+	record := e.record(ToFlutter, channel, data)
+	reply, err = e.messenger.SendWithReply(ctx, channel, data)
+	if err != nil {
+		return nil, err
+	}
+	e.recordReply(record, reply)
+	return reply, nil
+}
+
+// handlePlatformMessage records and answers the messages of the dart side.
+// It is called by the platform thread.
+func (e *Engine) handlePlatformMessage(channel string, data []byte, responseSender plugin.ResponseSender) error {
+	e.textInput.observe(channel, data)
+
+	record := e.record(FromFlutter, channel, data)
+	e.messagesLock.Lock()
+	handler := e.handlers[channel]
+	e.messagesLock.Unlock()
+
+	var reply []byte
+	if handler != nil {
+		reply = handler(data)
+	}
+	e.recordReply(record, reply)
+	responseSender.Send(reply)
+	return nil
+}
+
+func (e *Engine) record(direction Direction, channel string, data []byte) *Message {
+	record := &Message{
+		Direction: direction,
+		Channel:   channel,
+		Data:      data,
+	}
+	e.messagesLock.Lock()
+	e.messages = append(e.messages, record)
+	e.messagesLock.Unlock()
+	return record
+}
+
+func (e *Engine) recordReply(record *Message, reply []byte) {
+	e.messagesLock.Lock()
+	record.Reply = reply
+	record.Replied = true
+	e.messagesLock.Unlock()
+}
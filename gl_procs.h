@@ -0,0 +1,28 @@
+// The OpenGL declarations used by the package. The functions are resolved at
+// runtime with glfwGetProcAddress, no OpenGL library is linked.
+
+#ifndef GO_FLUTTER_GL_PROCS_H
+#define GO_FLUTTER_GL_PROCS_H
+
+#ifndef APIENTRY
+#if defined(_WIN32)
+#define APIENTRY __stdcall
+#else
+#define APIENTRY
+#endif
+#endif
+
+#define GL_TEXTURE_2D 0x0DE1
+#define GL_UNPACK_ROW_LENGTH 0x0CF2
+#define GL_UNSIGNED_BYTE 0x1401
+#define GL_RGBA 0x1908
+#define GL_BGRA 0x80E1
+#define GL_RGBA8 0x8058
+#define GL_LINEAR 0x2601
+#define GL_TEXTURE_MAG_FILTER 0x2800
+#define GL_TEXTURE_MIN_FILTER 0x2801
+#define GL_TEXTURE_WRAP_S 0x2802
+#define GL_TEXTURE_WRAP_T 0x2803
+#define GL_CLAMP_TO_EDGE 0x812F
+
+#endif
@@ -0,0 +1,40 @@
+package headless
+
+import (
+	"github.com/go-flutter-desktop/go-flutter/embedder"
+	"github.com/go-flutter-desktop/go-flutter/internal/embedding"
+)
+
+// The errors below are returned by Run when the application cannot start,
+// they are the same types as the errors of the flutter package.
+
+// InvalidAssetsPathError is returned when the flutter assets directory is not
+// set or cannot be read. Path is the directory, Err the cause.
+type InvalidAssetsPathError = embedding.InvalidAssetsPathError
+
+// MissingICUDataError is returned when the ICU data file is not set or
+// cannot be read. Path is the file, Err the cause.
+type MissingICUDataError = embedding.MissingICUDataError
+
+// InvalidWindowDimensionError is returned when the window dimension is
+// missing or not strictly positive.
+type InvalidWindowDimensionError = embedding.InvalidWindowDimensionError
+
+// EngineError is returned when the FlutterEngine refuses to start. Its Result
+// is embedder.KInvalidLibraryVersion when the engine library does not match
+// the version of the embedder API go-flutter is built with, and
+// embedder.KInvalidArguments when the engine rejects the project arguments.
+type EngineError = embedding.EngineError
+
+// IncompatibleOptionsError is returned when two options can't be used
+// together, e.g.: OptionFrameCallback with OptionOpenGLRenderer.
+type IncompatibleOptionsError = embedding.IncompatibleOptionsError
+
+// AOTSnapshotError is returned when a buffer of the AOT snapshot can't be
+// loaded. Snapshot is the name of the buffer, Path its file, Err the cause.
+type AOTSnapshotError = embedder.AOTSnapshotError
+
+// InvalidOptionError is returned when an option is given an incomplete
+// value, e.g.: an OpenGLRenderer without MakeCurrent callback. Missing are
+// the fields that must be set.
+type InvalidOptionError = embedding.InvalidOptionError
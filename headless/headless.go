@@ -0,0 +1,365 @@
+// Package headless runs a flutter application without window and without
+// glfw, e.g.: for golden-image tests or server side rendering. It does not
+// link against a window system, unlike the flutter package.
+//
+// The frames are rendered by the software renderer of the engine and given
+// to the frame callback of OptionFrameCallback, see PNGFrameWriter. The
+// window metrics are driven by the Go side: the initial size is set with
+// ApplicationWindowDimension, the pixel ratio with OptionPixelRatio, and
+// changed with Resize.
+//
+// Hosts owning an OpenGL context and a main loop, e.g.: game engines, render
+// the application in their scene with OptionOpenGLRenderer and RunHostDriven,
+// and pump the tasks of the engine with ProcessTasks.
+package headless
+
+import (
+	"fmt"
+	"image"
+	"image/png"
+	"log"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sync/atomic"
+	"time"
+
+	"github.com/go-flutter-desktop/go-flutter/embedder"
+	"github.com/go-flutter-desktop/go-flutter/internal/embedding"
+	"github.com/go-flutter-desktop/go-flutter/internal/messenger"
+	"github.com/go-flutter-desktop/go-flutter/internal/taskqueue"
+	"github.com/go-flutter-desktop/go-flutter/plugin"
+	"github.com/pkg/errors"
+)
+
+// loopInterval is the interval at which the platform thread of an
+// Application runs the pending tasks of the engine.
+const loopInterval = 5 * time.Millisecond
+
+// Plugin is a plugin of a headless application. The plugins of the flutter
+// package that only use the messenger implement it.
+type Plugin interface {
+	// InitPlugin is called before the engine runs. The messenger is used to
+	// register channel handlers, messages can be sent once Run has returned.
+	InitPlugin(messenger plugin.BinaryMessenger) error
+}
+
+// PluginCloser defines the interface for plugins that need to clean up
+// when the application stops.
+type PluginCloser interface {
+	// Any PluginCloser must also adhere to the Plugin interface.
+	Plugin
+
+	// ClosePlugin is called by Shutdown, before the FlutterEngine is shut
+	// down. Messages can't be sent anymore. It is also called when Run fails
+	// after InitPlugin.
+	ClosePlugin() error
+}
+
+// Application is a flutter application running headless. Its methods are
+// safe for concurrent use, except for the applications started with
+// RunHostDriven.
+//
+// The platform thread of the engine is owned by the Application, it runs the
+// channel handlers of the plugins. With RunHostDriven it is the thread of the
+// host.
+type Application struct {
+	engine    *embedder.FlutterEngine
+	renderer  *embedding.SoftwareRenderer
+	messenger *messenger.Messenger
+	tasks     *taskqueue.Queue
+	plugins   []Plugin
+
+	// hostDriven is set by RunHostDriven, the engine calls are made on the
+	// thread of the caller.
+	hostDriven bool
+
+	stop chan struct{}
+	done chan struct{}
+}
+
+// Run starts a flutter application without window. It returns once the
+// engine is running, the application runs until Shutdown is called.
+func Run(options ...Option) (*Application, error) {
+	a, c, err := newApplication(options)
+	if err != nil {
+		return nil, err
+	}
+
+	started := make(chan error, 1)
+	go a.run(c, started)
+	err = <-started
+	if err != nil {
+		<-a.done
+		return nil, err
+	}
+	return a, nil
+}
+
+// RunHostDriven starts a flutter application on the calling thread, for hosts
+// that own their main loop, e.g.: with OptionOpenGLRenderer. The calling
+// thread becomes the platform thread of the engine: it must be locked with
+// runtime.LockOSThread, call ProcessTasks regularly, and be the only thread
+// calling the methods of the Application.
+func RunHostDriven(options ...Option) (*Application, error) {
+	a, c, err := newApplication(options)
+	if err != nil {
+		return nil, err
+	}
+	a.hostDriven = true
+
+	err = a.start(c)
+	if err != nil {
+		return nil, err
+	}
+	return a, nil
+}
+
+// newApplication validates the options, initializes the plugins and sets up
+// the engine of an Application.
+func newApplication(options []Option) (*Application, config, error) {
+	var c config
+	c = c.merge(options...)
+	var err error
+	err = c.ExtractBundle()
+	if err != nil {
+		return nil, c, err
+	}
+	err = c.validate()
+	if err != nil {
+		return nil, c, err
+	}
+	if c.PixelRatio == 0 {
+		c.PixelRatio = 1.0
+	}
+
+	a := &Application{
+		tasks:   &taskqueue.Queue{},
+		plugins: c.Plugins,
+		stop:    make(chan struct{}),
+		done:    make(chan struct{}),
+	}
+	a.tasks.Start()
+
+	a.messenger = messenger.New(a.tasks)
+	a.messenger.Unclaimed = c.UnclaimedMessageHandler
+	a.messenger.LogUnhandled = c.LogUnhandledMessages
+	a.messenger.PanicOnReplyMisuse = c.PanicOnReplyMisuse
+	for i, p := range c.Plugins {
+		err = p.InitPlugin(a.messenger)
+		if err != nil {
+			a.tasks.Stop()
+			closePlugins(c.Plugins[:i])
+			return nil, c, errors.Wrapf(err, "failed to initialize plugin %T", p)
+		}
+	}
+
+	a.engine = embedder.NewFlutterEngine()
+	a.messenger.Engine = a.engine
+	a.engine.AssetsPath = c.AssetsPath
+	a.engine.IcuDataPath = c.ICUDataPath
+	a.engine.AOTSnapshot = c.AOTSnapshot
+	if gl := c.OpenGLRenderer; gl != nil {
+		a.engine.RendererType = embedder.KOpenGL
+		a.engine.FMakeCurrent = gl.MakeCurrent
+		a.engine.FClearCurrent = gl.ClearCurrent
+		a.engine.FPresent = gl.Present
+		a.engine.FFboCallback = gl.FBO
+		a.engine.FBOResetAfterPresent = gl.FBOResetAfterPresent
+		a.engine.FMakeResourceCurrent = gl.makeResourceCurrent
+		a.engine.FGLProcResolver = gl.ProcResolver
+	} else {
+		a.renderer = &embedding.SoftwareRenderer{FrameCallback: c.FrameCallback}
+		a.renderer.Resize(c.Width)
+		a.engine.RendererType = embedder.KSoftware
+		a.engine.FSurfacePresent = a.renderer.Present
+	}
+	a.engine.FPlatfromMessage = func(platMessage *embedder.PlatformMessage) bool {
+		if !a.messenger.HandlePlatformMessage(platMessage) {
+			a.messenger.HandleUnclaimedMessage(platMessage)
+			return false
+		}
+		return true
+	}
+	return a, c, nil
+}
+
+// start runs the engine on the calling thread, which becomes the platform
+// thread, and sends the initial window metrics.
+func (a *Application) start(c config) error {
+	err := a.engine.LoadAOTSnapshot()
+	if err != nil {
+		a.release()
+		return err
+	}
+	result := a.engine.Run(c.VMArguments)
+	if result != embedder.KSuccess {
+		a.release()
+		return &EngineError{
+			Result:      result,
+			AssetsPath:  c.AssetsPath,
+			ICUDataPath: c.ICUDataPath,
+			VMArguments: c.VMArguments,
+		}
+	}
+	a.engine.SendWindowMetricsEvent(embedder.WindowMetricsEvent{
+		Width:      c.Width,
+		Height:     c.Height,
+		PixelRatio: c.PixelRatio,
+	})
+	return nil
+}
+
+// run is the platform thread of the engine: it runs the engine and its
+// tasks until Shutdown is called.
+func (a *Application) run(c config, started chan<- error) {
+	// The engine tasks must be run by the thread that runs the engine.
+	runtime.LockOSThread()
+	defer runtime.UnlockOSThread()
+	defer close(a.done)
+
+	err := a.start(c)
+	started <- err
+	if err != nil {
+		return
+	}
+
+	ticker := time.NewTicker(loopInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-a.stop:
+			a.close()
+			return
+		case <-ticker.C:
+			a.ProcessTasks()
+		}
+	}
+}
+
+// ProcessTasks runs the pending tasks of the engine and of the plugins. The
+// host of an application started with RunHostDriven must call it regularly,
+// e.g.: once per iteration of its main loop. Run calls it by itself.
+func (a *Application) ProcessTasks() {
+	embedder.FlutterEngineFlushPendingTasksNow()
+	a.tasks.Run()
+}
+
+// close closes the plugins and shuts the engine down, on the platform thread.
+func (a *Application) close() {
+	a.tasks.Stop()
+	closePlugins(a.plugins)
+	a.engine.Shutdown()
+	a.engine.Release()
+}
+
+// release closes the plugins and releases the engine that failed to run.
+func (a *Application) release() {
+	a.tasks.Stop()
+	closePlugins(a.plugins)
+	a.engine.Release()
+}
+
+// closePlugins closes the plugins that satisfy the PluginCloser interface,
+// the errors are logged.
+func closePlugins(plugins []Plugin) {
+	for _, p := range plugins {
+		if closerPlugin, ok := p.(PluginCloser); ok {
+			err := closerPlugin.ClosePlugin()
+			if err != nil {
+				log.Printf("failed to close plugin %T: %v\n", p, err)
+			}
+		}
+	}
+}
+
+// do runs an engine call on the platform thread, waits for it and converts
+// its result to an error.
+func (a *Application) do(what string, call func() embedder.Result) error {
+	var result embedder.Result
+	if a.hostDriven {
+		result = call()
+	} else {
+		done := make(chan bool, 1)
+		err := a.tasks.Post(taskqueue.Task{
+			Fn: func() {
+				result = call()
+			},
+			Done: done,
+		})
+		if err != nil {
+			return errors.Wrapf(err, "failed to %s", what)
+		}
+		if !<-done {
+			return errors.Errorf("failed to %s: the application stopped", what)
+		}
+	}
+	if result != embedder.KSuccess {
+		return errors.Errorf("failed to %s: engine result %d", what, result)
+	}
+	return nil
+}
+
+// Resize sends new window metrics to the application, width and height are
+// in physical pixels. It waits for the metrics to be sent.
+func (a *Application) Resize(width int, height int, pixelRatio float64) error {
+	if width < 1 || height < 1 || pixelRatio <= 0 {
+		return errors.Errorf("invalid window metrics %dx%d@%v", width, height, pixelRatio)
+	}
+	return a.do("resize", func() embedder.Result {
+		if a.renderer != nil {
+			a.renderer.Resize(width)
+		}
+		return a.engine.SendWindowMetricsEvent(embedder.WindowMetricsEvent{
+			Width:      width,
+			Height:     height,
+			PixelRatio: pixelRatio,
+		})
+	})
+}
+
+// SendPointerEvent sends a pointer event to the application, the position
+// is in physical pixels. It waits for the event to be sent.
+func (a *Application) SendPointerEvent(event embedder.PointerEvent) error {
+	return a.do("send pointer event", func() embedder.Result {
+		return a.engine.SendPointerEvent(event)
+	})
+}
+
+// Shutdown stops the application and shuts the engine down. The plugins
+// implementing PluginCloser are closed first. It must be called once.
+func (a *Application) Shutdown() {
+	if a.hostDriven {
+		a.close()
+		return
+	}
+	close(a.stop)
+	<-a.done
+}
+
+// PNGFrameWriter returns a frame callback, for OptionFrameCallback, that
+// writes every frame to a PNG file in dir: frame-000001.png,
+// frame-000002.png, ... Errors are logged.
+func PNGFrameWriter(dir string) func(frame *image.RGBA) {
+	var count int64
+	return func(frame *image.RGBA) {
+		name := filepath.Join(dir, fmt.Sprintf("frame-%06d.png", atomic.AddInt64(&count, 1)))
+		err := writePNG(name, frame)
+		if err != nil {
+			log.Printf("failed to write frame: %v\n", err)
+		}
+	}
+}
+
+func writePNG(name string, img image.Image) error {
+	f, err := os.Create(name)
+	if err != nil {
+		return err
+	}
+	err = png.Encode(f, img)
+	if err != nil {
+		f.Close()
+		return errors.Wrapf(err, "encoding %s", name)
+	}
+	return f.Close()
+}
@@ -0,0 +1,75 @@
+package headless
+
+import "unsafe"
+
+// OpenGLRenderer is an OpenGL context supplied by the host, to render the
+// flutter application in the scene of a host that owns its window, its
+// context and its main loop, e.g.: a game engine. The callbacks are called by
+// the render thread of the engine.
+type OpenGLRenderer struct {
+	// MakeCurrent makes the context of the host current on the calling
+	// thread.
+	MakeCurrent func() bool
+	// ClearCurrent clears the current context of the calling thread.
+	ClearCurrent func() bool
+	// Present is called once a frame has been rendered into the framebuffer
+	// object returned by FBO.
+	Present func() bool
+	// FBO returns the framebuffer object the engine renders into, 0 for the
+	// default framebuffer.
+	FBO func() uint32
+	// FBOResetAfterPresent asks the engine to call FBO after every Present,
+	// for hosts that render into a different framebuffer object every frame.
+	FBOResetAfterPresent bool
+	// MakeResourceCurrent, when not nil, makes current on the calling thread
+	// a context sharing its resources with the context of the host. The
+	// engine uses it to upload textures without blocking the render thread.
+	MakeResourceCurrent func() bool
+	// ProcResolver returns the address of an OpenGL function, or nil.
+	ProcResolver func(name string) unsafe.Pointer
+}
+
+// OptionOpenGLRenderer renders the application with the OpenGL context of
+// the host instead of the software renderer. It is used with RunHostDriven,
+// the frames are not given to the frame callback: Run returns an
+// *IncompatibleOptionsError when it is used with OptionFrameCallback, and an
+// *InvalidOptionError when a required callback is missing.
+func OptionOpenGLRenderer(renderer OpenGLRenderer) Option {
+	return func(c *config) {
+		c.OpenGLRenderer = &renderer
+	}
+}
+
+// validate returns an *InvalidOptionError naming the required callbacks that
+// are missing.
+func (r *OpenGLRenderer) validate() error {
+	var missing []string
+	if r.MakeCurrent == nil {
+		missing = append(missing, "MakeCurrent")
+	}
+	if r.ClearCurrent == nil {
+		missing = append(missing, "ClearCurrent")
+	}
+	if r.Present == nil {
+		missing = append(missing, "Present")
+	}
+	if r.FBO == nil {
+		missing = append(missing, "FBO")
+	}
+	if r.ProcResolver == nil {
+		missing = append(missing, "ProcResolver")
+	}
+	if len(missing) > 0 {
+		return &InvalidOptionError{Option: "OptionOpenGLRenderer", Missing: missing}
+	}
+	return nil
+}
+
+// makeResourceCurrent satisfies embedder.FlutterEngine.FMakeResourceCurrent,
+// the engine does without resource context when it returns false.
+func (r *OpenGLRenderer) makeResourceCurrent() bool {
+	if r.MakeResourceCurrent == nil {
+		return false
+	}
+	return r.MakeResourceCurrent()
+}
@@ -0,0 +1,155 @@
+package headless
+
+import (
+	"image"
+
+	"github.com/go-flutter-desktop/go-flutter/internal/embedding"
+	"github.com/go-flutter-desktop/go-flutter/plugin"
+)
+
+type config struct {
+	embedding.Config
+
+	PixelRatio    float64
+	Plugins       []Plugin
+	FrameCallback func(frame *image.RGBA)
+
+	OpenGLRenderer *OpenGLRenderer
+
+	UnclaimedMessageHandler UnclaimedMessageHandlerFunc
+}
+
+func (c config) merge(options ...Option) config {
+	for _, option := range options {
+		option(&c)
+	}
+
+	return c
+}
+
+// validate returns the errors of the options shared with the flutter
+// package, see embedding.Config.Validate, or an error when the window
+// dimension is missing or when the renderer options don't match.
+func (c config) validate() error {
+	err := c.Config.Validate()
+	if err != nil {
+		return err
+	}
+	if c.Width < 1 || c.Height < 1 {
+		return &InvalidWindowDimensionError{Width: c.Width, Height: c.Height}
+	}
+	if c.OpenGLRenderer != nil {
+		if c.FrameCallback != nil {
+			return &IncompatibleOptionsError{Option: "OptionFrameCallback", OtherOption: "OptionOpenGLRenderer"}
+		}
+		return c.OpenGLRenderer.validate()
+	}
+	return nil
+}
+
+// Option for the headless applications, the options mirror those of the
+// flutter package.
+type Option func(*config)
+
+// sharedOption wraps an option of the config shared with the flutter
+// package.
+func sharedOption(option embedding.Option) Option {
+	return func(c *config) {
+		option(&c.Config)
+	}
+}
+
+// ProjectAssetsPath specify the flutter assets directory.
+// An invalid directory is reported by Run as an *InvalidAssetsPathError.
+func ProjectAssetsPath(p string) Option {
+	return sharedOption(embedding.ProjectAssetsPath(p))
+}
+
+// ApplicationICUDataPath specify the path to the ICUData.
+// A missing file is reported by Run as a *MissingICUDataError.
+func ApplicationICUDataPath(p string) Option {
+	return sharedOption(embedding.ApplicationICUDataPath(p))
+}
+
+// OptionBundle runs the application from a zip archive produced by the
+// go-flutter-bundle command, see flutter.OptionBundle. It replaces
+// ProjectAssetsPath and ApplicationICUDataPath.
+func OptionBundle(archive []byte) Option {
+	return sharedOption(embedding.OptionBundle(archive))
+}
+
+// OptionVMArguments specify the arguments to the Dart VM.
+func OptionVMArguments(a []string) Option {
+	return sharedOption(embedding.OptionVMArguments(a))
+}
+
+// OptionAOTSnapshotFiles runs an application compiled ahead of time, see
+// flutter.OptionAOTSnapshotFiles.
+func OptionAOTSnapshotFiles(vmData, vmInstructions, isolateData, isolateInstructions string) Option {
+	return sharedOption(embedding.OptionAOTSnapshotFiles(vmData, vmInstructions, isolateData, isolateInstructions))
+}
+
+// OptionAOTSnapshotBuffers runs an application compiled ahead of time from
+// in-memory snapshots, see flutter.OptionAOTSnapshotBuffers.
+func OptionAOTSnapshotBuffers(vmData, vmInstructions, isolateData, isolateInstructions []byte) Option {
+	return sharedOption(embedding.OptionAOTSnapshotBuffers(vmData, vmInstructions, isolateData, isolateInstructions))
+}
+
+// ApplicationWindowDimension specify the initial size of the window, in
+// physical pixels. It is required, a dimension lower than 1 is reported by
+// Run as an *InvalidWindowDimensionError.
+func ApplicationWindowDimension(width int, height int) Option {
+	return sharedOption(embedding.ApplicationWindowDimension(width, height))
+}
+
+// OptionPixelRatio sets the initial pixel ratio of the window, 1.0 by
+// default.
+func OptionPixelRatio(ratio float64) Option {
+	return func(c *config) {
+		c.PixelRatio = ratio
+	}
+}
+
+// OptionFrameCallback sets the func receiving every frame rendered by the
+// engine, see PNGFrameWriter. It is called by the render thread of the
+// engine, the frame can be kept. Frames rendered with OptionOpenGLRenderer
+// are not given to the frame callback.
+func OptionFrameCallback(frameCallback func(frame *image.RGBA)) Option {
+	return func(c *config) {
+		c.FrameCallback = frameCallback
+	}
+}
+
+// AddPlugin adds a plugin to the application.
+func AddPlugin(p Plugin) Option {
+	return func(c *config) {
+		c.Plugins = append(c.Plugins, p)
+	}
+}
+
+// UnclaimedMessageHandlerFunc handles the binary messages sent on the
+// channels without handler, see plugin.ChannelHandlerFunc for the reply.
+type UnclaimedMessageHandlerFunc func(channel string, binaryMessage []byte, responseSender plugin.ResponseSender) (err error)
+
+// OptionUnclaimedMessageHandler sets the handler of the messages sent on the
+// channels that no plugin handles, instead of an empty reply. It is called
+// by the platform thread of the engine.
+func OptionUnclaimedMessageHandler(handler UnclaimedMessageHandlerFunc) Option {
+	return func(c *config) {
+		c.UnclaimedMessageHandler = handler
+	}
+}
+
+// OptionLogUnhandledMessages logs the channel and method of the messages sent
+// by the FlutterEngine that no plugin has handled. Each channel/method pair is
+// logged once. Useful to find missing plugins during development.
+func OptionLogUnhandledMessages() Option {
+	return sharedOption(embedding.OptionLogUnhandledMessages())
+}
+
+// OptionPanicOnReplyMisuse panics, instead of logging, when a plugin answers
+// a message twice. In debug builds (`-tags debug`) it also panics when a
+// plugin drops a message without reply.
+func OptionPanicOnReplyMisuse() Option {
+	return sharedOption(embedding.OptionPanicOnReplyMisuse())
+}
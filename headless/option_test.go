@@ -0,0 +1,169 @@
+package headless
+
+import (
+	"archive/zip"
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"unsafe"
+
+	"github.com/pkg/errors"
+)
+
+// testProjectOptions returns the options of a 800x600 project made of an
+// empty assets directory and ICU data file, and a func removing them.
+func testProjectOptions(t *testing.T) ([]Option, func()) {
+	t.Helper()
+	dir, err := ioutil.TempDir("", "go-flutter-test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	cleanup := func() { os.RemoveAll(dir) }
+	assetsPath := filepath.Join(dir, "flutter_assets")
+	icuDataPath := filepath.Join(dir, "icudtl.dat")
+	err = os.Mkdir(assetsPath, 0755)
+	if err == nil {
+		err = ioutil.WriteFile(icuDataPath, nil, 0644)
+	}
+	if err != nil {
+		cleanup()
+		t.Fatal(err)
+	}
+	return []Option{
+		ProjectAssetsPath(assetsPath),
+		ApplicationICUDataPath(icuDataPath),
+		ApplicationWindowDimension(800, 600),
+	}, cleanup
+}
+
+func testOpenGLRenderer() OpenGLRenderer {
+	return OpenGLRenderer{
+		MakeCurrent:  func() bool { return true },
+		ClearCurrent: func() bool { return true },
+		Present:      func() bool { return true },
+		FBO:          func() uint32 { return 0 },
+		ProcResolver: func(name string) unsafe.Pointer { return nil },
+	}
+}
+
+func TestValidateOpenGLRenderer(t *testing.T) {
+	projectOptions, cleanup := testProjectOptions(t)
+	defer cleanup()
+
+	var c config
+	c = c.merge(append(projectOptions, OptionOpenGLRenderer(testOpenGLRenderer()))...)
+	err := c.validate()
+	if err != nil {
+		t.Fatalf("validate returned %v for a complete OpenGL renderer", err)
+	}
+
+	incomplete := testOpenGLRenderer()
+	incomplete.ClearCurrent = nil
+	incomplete.ProcResolver = nil
+	c = config{}
+	c = c.merge(append(projectOptions, OptionOpenGLRenderer(incomplete))...)
+	err = c.validate()
+	invalidErr, ok := errors.Cause(err).(*InvalidOptionError)
+	if !ok {
+		t.Fatalf("validate returned %v, expected an *InvalidOptionError", err)
+	}
+	if invalidErr.Option != "OptionOpenGLRenderer" || !reflect.DeepEqual(invalidErr.Missing, []string{"ClearCurrent", "ProcResolver"}) {
+		t.Fatalf("invalid option %s missing %v, expected OptionOpenGLRenderer missing ClearCurrent and ProcResolver", invalidErr.Option, invalidErr.Missing)
+	}
+
+	c = config{}
+	c = c.merge(append(projectOptions,
+		OptionOpenGLRenderer(testOpenGLRenderer()),
+		OptionFrameCallback(PNGFrameWriter(os.TempDir())),
+	)...)
+	err = c.validate()
+	incompatibleErr, ok := errors.Cause(err).(*IncompatibleOptionsError)
+	if !ok {
+		t.Fatalf("validate returned %v, expected an *IncompatibleOptionsError", err)
+	}
+	if incompatibleErr.Option != "OptionFrameCallback" {
+		t.Fatalf("incompatible option %s, expected OptionFrameCallback", incompatibleErr.Option)
+	}
+}
+
+func TestValidateLastOptionWins(t *testing.T) {
+	projectOptions, cleanup := testProjectOptions(t)
+	defer cleanup()
+
+	var c config
+	c = c.merge(append([]Option{ApplicationWindowDimension(0, 0)}, projectOptions...)...)
+	err := c.validate()
+	if err != nil {
+		t.Fatalf("validate returned %v, the valid dimension was set last", err)
+	}
+
+	c = config{}
+	c = c.merge(append(projectOptions, ApplicationWindowDimension(0, 0))...)
+	err = c.validate()
+	if _, ok := errors.Cause(err).(*InvalidWindowDimensionError); !ok {
+		t.Fatalf("validate returned %v, expected an *InvalidWindowDimensionError", err)
+	}
+}
+
+// testBundleArchive returns a bundle archive holding an empty assets
+// directory and ICU data file. The user cache directory, where the archive is
+// extracted, is moved to a temporary directory until the returned func is
+// called.
+func testBundleArchive(t *testing.T) ([]byte, func()) {
+	t.Helper()
+	var buf bytes.Buffer
+	w := zip.NewWriter(&buf)
+	for _, name := range []string{"flutter_assets/AssetManifest.json", "icudtl.dat"} {
+		_, err := w.Create(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	err := w.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cacheDir, err := ioutil.TempDir("", "go-flutter-test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	oldCacheHome, oldHome := os.Getenv("XDG_CACHE_HOME"), os.Getenv("HOME")
+	os.Setenv("XDG_CACHE_HOME", cacheDir)
+	os.Setenv("HOME", cacheDir)
+	return buf.Bytes(), func() {
+		os.Setenv("XDG_CACHE_HOME", oldCacheHome)
+		os.Setenv("HOME", oldHome)
+		os.RemoveAll(cacheDir)
+	}
+}
+
+func TestValidateBundleReplacesPaths(t *testing.T) {
+	archive, cleanup := testBundleArchive(t)
+	defer cleanup()
+
+	var c config
+	c = c.merge(
+		ProjectAssetsPath("/nonexistent/flutter_assets"),
+		ApplicationICUDataPath("/nonexistent/icudtl.dat"),
+		ApplicationWindowDimension(800, 600),
+		OptionBundle(archive),
+	)
+	err := c.ExtractBundle()
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = c.validate()
+	if err != nil {
+		t.Fatalf("validate returned %v, the bundle replaces the invalid paths", err)
+	}
+	if _, err = os.Stat(filepath.Join(c.AssetsPath, "AssetManifest.json")); err != nil {
+		t.Fatalf("assets path %s is not the extracted bundle: %v", c.AssetsPath, err)
+	}
+	if _, err = os.Stat(c.ICUDataPath); err != nil {
+		t.Fatalf("ICU data path %s is not the extracted bundle: %v", c.ICUDataPath, err)
+	}
+}
@@ -0,0 +1,183 @@
+package embedding
+
+import (
+	"archive/zip"
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"hash/crc32"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// Layout of the bundle archives, as produced by the go-flutter-bundle
+// command.
+const (
+	bundleAssetsDir   = "flutter_assets"
+	bundleICUDataFile = "icudtl.dat"
+)
+
+// bundleMarkerFile is written once a bundle is fully extracted and verified,
+// the extracted files are then trusted.
+const bundleMarkerFile = ".extracted"
+
+// ExtractBundle extracts the bundle archive to the user cache directory and
+// returns the paths of the assets and of the ICU data.
+//
+// The archive is extracted once to a directory named after its checksum. At
+// the next starts only the sizes of the extracted files are checked against
+// the archive entries, the bundle is extracted again when a file is missing
+// or has another size.
+func ExtractBundle(archive []byte) (assetsPath string, icuDataPath string, err error) {
+	cacheDir, err := os.UserCacheDir()
+	if err != nil {
+		return "", "", errors.Wrap(err, "failed to locate the user cache directory")
+	}
+	return extractBundleTo(filepath.Join(cacheDir, "go-flutter"), archive)
+}
+
+func extractBundleTo(parentDir string, archive []byte) (assetsPath string, icuDataPath string, err error) {
+	reader, err := zip.NewReader(bytes.NewReader(archive), int64(len(archive)))
+	if err != nil {
+		return "", "", errors.Wrap(err, "invalid bundle archive")
+	}
+	var hasAssets, hasICUData bool
+	for _, f := range reader.File {
+		name := f.Name
+		if strings.HasPrefix(name, "/") || strings.Contains(name, "\\") || strings.Contains("/"+name+"/", "/../") {
+			return "", "", errors.Errorf("invalid bundle archive: unsafe file name %s", name)
+		}
+		hasAssets = hasAssets || strings.HasPrefix(name, bundleAssetsDir+"/")
+		hasICUData = hasICUData || name == bundleICUDataFile
+	}
+	if !hasAssets || !hasICUData {
+		return "", "", errors.Errorf("invalid bundle archive: %s or %s is missing", bundleAssetsDir, bundleICUDataFile)
+	}
+
+	sum := sha256.Sum256(archive)
+	dir := filepath.Join(parentDir, "bundle-"+hex.EncodeToString(sum[:8]))
+	assetsPath = filepath.Join(dir, bundleAssetsDir)
+	icuDataPath = filepath.Join(dir, bundleICUDataFile)
+
+	if _, err = os.Stat(dir); err == nil {
+		if verifyBundle(dir, reader) == nil {
+			return assetsPath, icuDataPath, nil
+		}
+		err = os.RemoveAll(dir)
+		if err != nil {
+			return "", "", errors.Wrap(err, "failed to remove the corrupted bundle cache")
+		}
+	}
+
+	err = os.MkdirAll(parentDir, 0755)
+	if err != nil {
+		return "", "", errors.Wrap(err, "failed to create the bundle cache directory")
+	}
+	// Extract to a temporary directory first, so that concurrent starts
+	// never see a partially extracted bundle.
+	tmpDir, err := ioutil.TempDir(parentDir, "extract-")
+	if err != nil {
+		return "", "", errors.Wrap(err, "failed to create the bundle cache directory")
+	}
+	defer os.RemoveAll(tmpDir)
+	for _, f := range reader.File {
+		err = extractBundleFile(tmpDir, f)
+		if err != nil {
+			return "", "", errors.Wrapf(err, "failed to extract %s", f.Name)
+		}
+	}
+	err = ioutil.WriteFile(filepath.Join(tmpDir, bundleMarkerFile), nil, 0644)
+	if err != nil {
+		return "", "", errors.Wrap(err, "failed to mark the bundle as extracted")
+	}
+	err = os.Rename(tmpDir, dir)
+	if err != nil {
+		// Another process may have extracted the same bundle meanwhile.
+		if verifyBundle(dir, reader) == nil {
+			return assetsPath, icuDataPath, nil
+		}
+		return "", "", errors.Wrap(err, "failed to move the extracted bundle")
+	}
+	return assetsPath, icuDataPath, nil
+}
+
+func extractBundleFile(dir string, f *zip.File) error {
+	path := filepath.Join(dir, filepath.FromSlash(f.Name))
+	if f.FileInfo().IsDir() {
+		return os.MkdirAll(path, 0755)
+	}
+	err := os.MkdirAll(filepath.Dir(path), 0755)
+	if err != nil {
+		return err
+	}
+	src, err := f.Open()
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+	dst, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0644)
+	if err != nil {
+		return err
+	}
+	_, err = io.Copy(dst, src)
+	if err != nil {
+		dst.Close()
+		return err
+	}
+	return dst.Close()
+}
+
+// verifyBundle checks the extracted files of the bundle directory against
+// the archive. When the directory holds the marker file the files are
+// trusted and only their sizes are checked, so that the starts don't read
+// the whole bundle. Otherwise their CRC-32 checksums are verified as well,
+// and the marker file is written once they match.
+func verifyBundle(dir string, reader *zip.Reader) error {
+	markerPath := filepath.Join(dir, bundleMarkerFile)
+	_, err := os.Stat(markerPath)
+	if err == nil {
+		return checkBundleSizes(dir, reader)
+	}
+	for _, f := range reader.File {
+		if f.FileInfo().IsDir() {
+			continue
+		}
+		file, err := os.Open(filepath.Join(dir, filepath.FromSlash(f.Name)))
+		if err != nil {
+			return err
+		}
+		hash := crc32.NewIEEE()
+		size, err := io.Copy(hash, file)
+		file.Close()
+		if err != nil {
+			return err
+		}
+		if uint64(size) != f.UncompressedSize64 || hash.Sum32() != f.CRC32 {
+			return errors.Errorf("%s does not match the bundle archive", f.Name)
+		}
+	}
+	return ioutil.WriteFile(markerPath, nil, 0644)
+}
+
+// checkBundleSizes checks the sizes of the extracted files of the bundle
+// directory against the archive.
+func checkBundleSizes(dir string, reader *zip.Reader) error {
+	for _, f := range reader.File {
+		if f.FileInfo().IsDir() {
+			continue
+		}
+		info, err := os.Stat(filepath.Join(dir, filepath.FromSlash(f.Name)))
+		if err != nil {
+			return err
+		}
+		if uint64(info.Size()) != f.UncompressedSize64 {
+			return errors.Errorf("%s does not match the bundle archive", f.Name)
+		}
+	}
+	return nil
+}
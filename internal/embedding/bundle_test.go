@@ -0,0 +1,149 @@
+package embedding
+
+import (
+	"archive/zip"
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func testBundleArchive(t *testing.T, files map[string]string) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	w := zip.NewWriter(&buf)
+	for name, content := range files {
+		f, err := w.Create(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		_, err = f.Write([]byte(content))
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	err := w.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func readTestFile(t *testing.T, path string) string {
+	t.Helper()
+	content, err := ioutil.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(content)
+}
+
+func TestExtractBundle(t *testing.T) {
+	parentDir, err := ioutil.TempDir("", "go-flutter-test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(parentDir)
+
+	archive := testBundleArchive(t, map[string]string{
+		"flutter_assets/kernel_blob.bin": "kernel",
+		"icudtl.dat":                     "icu",
+	})
+	assetsPath, icuDataPath, err := extractBundleTo(parentDir, archive)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if content := readTestFile(t, filepath.Join(assetsPath, "kernel_blob.bin")); content != "kernel" {
+		t.Fatalf("extracted kernel_blob.bin %q, expected %q", content, "kernel")
+	}
+
+	// A modified file is repaired.
+	err = ioutil.WriteFile(icuDataPath, []byte("modified"), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, _, err = extractBundleTo(parentDir, archive)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if content := readTestFile(t, icuDataPath); content != "icu" {
+		t.Fatalf("repaired icudtl.dat %q, expected %q", content, "icu")
+	}
+
+	// A deleted file is repaired.
+	err = os.Remove(filepath.Join(assetsPath, "kernel_blob.bin"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, _, err = extractBundleTo(parentDir, archive)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if content := readTestFile(t, filepath.Join(assetsPath, "kernel_blob.bin")); content != "kernel" {
+		t.Fatalf("repaired kernel_blob.bin %q, expected %q", content, "kernel")
+	}
+
+	// The files of a marked bundle are trusted, only their sizes are
+	// checked.
+	err = ioutil.WriteFile(icuDataPath, []byte("ICU"), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, _, err = extractBundleTo(parentDir, archive)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if content := readTestFile(t, icuDataPath); content != "ICU" {
+		t.Fatalf("trusted icudtl.dat %q, expected %q", content, "ICU")
+	}
+
+	// The files of a bundle without marker file are verified, and the bundle
+	// is extracted again when they don't match.
+	markerPath := filepath.Join(filepath.Dir(icuDataPath), bundleMarkerFile)
+	err = os.Remove(markerPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, _, err = extractBundleTo(parentDir, archive)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if content := readTestFile(t, icuDataPath); content != "icu" {
+		t.Fatalf("extracted again icudtl.dat %q, expected %q", content, "icu")
+	}
+
+	// A bundle without marker file whose files match is marked.
+	err = os.Remove(markerPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, _, err = extractBundleTo(parentDir, archive)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = os.Stat(markerPath); err != nil {
+		t.Fatalf("verified bundle not marked: %v", err)
+	}
+}
+
+func TestExtractBundleInvalid(t *testing.T) {
+	tests := map[string]map[string]string{
+		"missing ICU data": {"flutter_assets/kernel_blob.bin": "kernel"},
+		"missing assets":   {"icudtl.dat": "icu"},
+		"unsafe name":      {"flutter_assets/../../evil": "", "icudtl.dat": "icu"},
+	}
+	for name, files := range tests {
+		t.Run(name, func(t *testing.T) {
+			parentDir, err := ioutil.TempDir("", "go-flutter-test")
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer os.RemoveAll(parentDir)
+			_, _, err = extractBundleTo(parentDir, testBundleArchive(t, files))
+			if err == nil {
+				t.Fatal("invalid bundle archive extracted")
+			}
+		})
+	}
+}
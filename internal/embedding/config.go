@@ -0,0 +1,150 @@
+package embedding
+
+import (
+	"github.com/go-flutter-desktop/go-flutter/embedder"
+	"github.com/pkg/errors"
+)
+
+// Config holds the options shared by the flutter and the headless packages,
+// both embed it in their config. The exported options of both packages wrap
+// the Options below.
+type Config struct {
+	AssetsPath           string
+	ICUDataPath          string
+	Bundle               []byte
+	AOTSnapshot          *embedder.AOTSnapshot
+	VMArguments          []string
+	Width                int
+	Height               int
+	LogUnhandledMessages bool
+	PanicOnReplyMisuse   bool
+
+	// errors of the options, reported by Validate. They are recorded per
+	// field: a valid option overrides the error of a previous one.
+	assetsPathErr      error
+	icuDataPathErr     error
+	windowDimensionErr error
+}
+
+// Option sets a field of the Config.
+type Option func(*Config)
+
+// ExtractBundle extracts the archive given by OptionBundle. The extracted
+// paths replace those given by ProjectAssetsPath and ApplicationICUDataPath,
+// along with their errors.
+func (c *Config) ExtractBundle() error {
+	if c.Bundle == nil {
+		return nil
+	}
+	assetsPath, icuDataPath, err := ExtractBundle(c.Bundle)
+	if err != nil {
+		return errors.Wrap(err, "failed to extract bundle")
+	}
+	c.AssetsPath, c.assetsPathErr = assetsPath, nil
+	c.ICUDataPath, c.icuDataPathErr = icuDataPath, nil
+	return nil
+}
+
+// Validate returns the errors recorded by the options, or an error when the
+// assets or the ICU data are missing. The window dimension is only checked
+// when it is set.
+func (c Config) Validate() error {
+	for _, err := range []error{c.assetsPathErr, c.icuDataPathErr, c.windowDimensionErr} {
+		if err != nil {
+			return err
+		}
+	}
+	if c.AssetsPath == "" {
+		return &InvalidAssetsPathError{}
+	}
+	if c.ICUDataPath == "" {
+		return &MissingICUDataError{}
+	}
+	return nil
+}
+
+// ProjectAssetsPath sets the flutter assets directory, an invalid directory
+// is recorded as an *InvalidAssetsPathError.
+func ProjectAssetsPath(p string) Option {
+	err := CheckAssetsPath(p)
+	return func(c *Config) {
+		c.AssetsPath = p
+		c.assetsPathErr = err
+	}
+}
+
+// ApplicationICUDataPath sets the path to the ICU data, a missing file is
+// recorded as a *MissingICUDataError.
+func ApplicationICUDataPath(p string) Option {
+	err := CheckICUDataPath(p)
+	return func(c *Config) {
+		c.ICUDataPath = p
+		c.icuDataPathErr = err
+	}
+}
+
+// ApplicationWindowDimension sets the initial size of the window, a
+// dimension lower than 1 is recorded as an *InvalidWindowDimensionError.
+func ApplicationWindowDimension(width int, height int) Option {
+	return func(c *Config) {
+		c.Width = width
+		c.Height = height
+		c.windowDimensionErr = nil
+		if width < 1 || height < 1 {
+			c.windowDimensionErr = &InvalidWindowDimensionError{Width: width, Height: height}
+		}
+	}
+}
+
+// OptionBundle sets the bundle archive extracted by ExtractBundle.
+func OptionBundle(archive []byte) Option {
+	return func(c *Config) {
+		c.Bundle = archive
+	}
+}
+
+// OptionVMArguments sets the arguments to the Dart VM.
+func OptionVMArguments(a []string) Option {
+	return func(c *Config) {
+		// First should be argument is argv[0]
+		c.VMArguments = append([]string{""}, a...)
+	}
+}
+
+// OptionAOTSnapshotFiles sets the files of an AOT snapshot.
+func OptionAOTSnapshotFiles(vmData, vmInstructions, isolateData, isolateInstructions string) Option {
+	return func(c *Config) {
+		c.AOTSnapshot = &embedder.AOTSnapshot{
+			VMData:              embedder.SnapshotBuffer{Path: vmData},
+			VMInstructions:      embedder.SnapshotBuffer{Path: vmInstructions},
+			IsolateData:         embedder.SnapshotBuffer{Path: isolateData},
+			IsolateInstructions: embedder.SnapshotBuffer{Path: isolateInstructions},
+		}
+	}
+}
+
+// OptionAOTSnapshotBuffers sets the in-memory buffers of an AOT snapshot.
+func OptionAOTSnapshotBuffers(vmData, vmInstructions, isolateData, isolateInstructions []byte) Option {
+	return func(c *Config) {
+		c.AOTSnapshot = &embedder.AOTSnapshot{
+			VMData:              embedder.SnapshotBuffer{Data: vmData},
+			VMInstructions:      embedder.SnapshotBuffer{Data: vmInstructions},
+			IsolateData:         embedder.SnapshotBuffer{Data: isolateData},
+			IsolateInstructions: embedder.SnapshotBuffer{Data: isolateInstructions},
+		}
+	}
+}
+
+// OptionLogUnhandledMessages logs the messages that no plugin has handled.
+func OptionLogUnhandledMessages() Option {
+	return func(c *Config) {
+		c.LogUnhandledMessages = true
+	}
+}
+
+// OptionPanicOnReplyMisuse panics, instead of logging, on reply misuses.
+func OptionPanicOnReplyMisuse() Option {
+	return func(c *Config) {
+		c.PanicOnReplyMisuse = true
+	}
+}
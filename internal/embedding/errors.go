@@ -0,0 +1,128 @@
+package embedding
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/go-flutter-desktop/go-flutter/embedder"
+	"github.com/pkg/errors"
+)
+
+// The errors below are returned by the flutter and the headless packages when
+// the application cannot start, both packages alias them.
+
+// InvalidAssetsPathError is returned when the flutter assets directory is not
+// set or cannot be read.
+type InvalidAssetsPathError struct {
+	Path string
+	Err  error
+}
+
+// Error implements the error interface.
+func (e *InvalidAssetsPathError) Error() string {
+	if e.Path == "" {
+		return "the flutter assets path is not set, use ProjectAssetsPath"
+	}
+	return fmt.Sprintf("invalid flutter assets path %s: %v", e.Path, e.Err)
+}
+
+// MissingICUDataError is returned when the ICU data file is not set or
+// cannot be read.
+type MissingICUDataError struct {
+	Path string
+	Err  error
+}
+
+// Error implements the error interface.
+func (e *MissingICUDataError) Error() string {
+	if e.Path == "" {
+		return "the ICU data path is not set, use ApplicationICUDataPath"
+	}
+	return fmt.Sprintf("missing ICU data %s: %v", e.Path, e.Err)
+}
+
+// InvalidWindowDimensionError is returned when the initial window dimension
+// is not strictly positive.
+type InvalidWindowDimensionError struct {
+	Width  int
+	Height int
+}
+
+// Error implements the error interface.
+func (e *InvalidWindowDimensionError) Error() string {
+	return fmt.Sprintf("invalid window dimension %dx%d, width and height must be greater than 0", e.Width, e.Height)
+}
+
+// EngineError is returned when the FlutterEngine refuses to start.
+//
+// Result is embedder.KInvalidLibraryVersion when the engine library does not
+// match the version of the embedder API go-flutter is built with, and
+// embedder.KInvalidArguments when the engine rejects the project arguments,
+// for instance assets that are not a flutter build output. An AOT snapshot
+// that can't be loaded is reported as an *embedder.AOTSnapshotError.
+type EngineError struct {
+	Result      embedder.Result
+	AssetsPath  string
+	ICUDataPath string
+	VMArguments []string
+}
+
+// Error implements the error interface.
+func (e *EngineError) Error() string {
+	switch e.Result {
+	case embedder.KInvalidLibraryVersion:
+		return "failed to run the FlutterEngine: the engine library version does not match the go-flutter embedder, update the engine library"
+	case embedder.KInvalidArguments:
+		return fmt.Sprintf("failed to run the FlutterEngine: invalid arguments (assets path %s, ICU data path %s, VM arguments %q)",
+			e.AssetsPath, e.ICUDataPath, e.VMArguments)
+	default:
+		return fmt.Sprintf("failed to run the FlutterEngine: engine result %d", e.Result)
+	}
+}
+
+// IncompatibleOptionsError is returned when two options can't be used
+// together.
+type IncompatibleOptionsError struct {
+	Option      string
+	OtherOption string
+}
+
+// Error implements the error interface.
+func (e *IncompatibleOptionsError) Error() string {
+	return fmt.Sprintf("%s cannot be used with %s", e.Option, e.OtherOption)
+}
+
+// InvalidOptionError is returned when an option is given an incomplete
+// value. Missing are the fields of the value that must be set.
+type InvalidOptionError struct {
+	Option  string
+	Missing []string
+}
+
+// Error implements the error interface.
+func (e *InvalidOptionError) Error() string {
+	return fmt.Sprintf("invalid %s: %s must be set", e.Option, strings.Join(e.Missing, ", "))
+}
+
+// CheckAssetsPath returns an *InvalidAssetsPathError when path is not a
+// readable directory.
+func CheckAssetsPath(path string) error {
+	info, err := os.Stat(path)
+	if err == nil && !info.IsDir() {
+		err = errors.New("not a directory")
+	}
+	if err != nil {
+		return &InvalidAssetsPathError{Path: path, Err: err}
+	}
+	return nil
+}
+
+// CheckICUDataPath returns a *MissingICUDataError when path does not exist.
+func CheckICUDataPath(path string) error {
+	_, err := os.Stat(path)
+	if err != nil {
+		return &MissingICUDataError{Path: path, Err: err}
+	}
+	return nil
+}
@@ -0,0 +1,70 @@
+// Package embedding holds the parts of go-flutter shared by the glfw
+// embedder and the headless embedder. It must not depend on glfw, the
+// headless embedder runs on machines without window system.
+package embedding
+
+import (
+	"image"
+	"sync/atomic"
+)
+
+// SoftwareRenderer receives the frames of the software renderer of the
+// engine. Present is called by the render thread of the engine, Resize by
+// the thread sending the window metrics.
+type SoftwareRenderer struct {
+	// Draw, when not nil, draws the BGRA pixels of the frame. The buffer
+	// must not be kept after the call.
+	Draw func(buffer []byte, width int, height int, rowBytes int)
+	// FrameCallback, when not nil, receives a copy of every frame.
+	FrameCallback func(frame *image.RGBA)
+
+	// width is the width of the surface in the last window metrics sent to
+	// the engine, accessed atomically.
+	width int32
+}
+
+// Resize records the width of the surface, it must be called when window
+// metrics are sent to the engine. The rows of the frames may be padded, the
+// width of the frames can't be deduced from their row bytes.
+func (r *SoftwareRenderer) Resize(width int) {
+	atomic.StoreInt32(&r.width, int32(width))
+}
+
+// Present satisfies embedder.FlutterEngine.FSurfacePresent. The buffer holds
+// the pixels in the N32 format of the engine, BGRA on the little endian
+// platforms supported by go-flutter.
+func (r *SoftwareRenderer) Present(buffer []byte, rowBytes int, height int) bool {
+	width := int(atomic.LoadInt32(&r.width))
+	if width == 0 || width > rowBytes/4 {
+		// frame rendered before the metrics, or with previous metrics
+		width = rowBytes / 4
+	}
+	if height == 0 || width == 0 {
+		return true
+	}
+
+	if r.Draw != nil {
+		r.Draw(buffer, width, height, rowBytes)
+	}
+
+	if r.FrameCallback != nil {
+		r.FrameCallback(BGRAToRGBA(buffer, width, height, rowBytes))
+	}
+	return true
+}
+
+// BGRAToRGBA copies the BGRA pixels of a frame to a new RGBA image.
+func BGRAToRGBA(buffer []byte, width int, height int, rowBytes int) *image.RGBA {
+	frame := image.NewRGBA(image.Rect(0, 0, width, height))
+	for y := 0; y < height; y++ {
+		src := buffer[y*rowBytes : y*rowBytes+width*4]
+		dst := frame.Pix[y*frame.Stride : y*frame.Stride+width*4]
+		for i := 0; i < len(src); i += 4 {
+			dst[i] = src[i+2]
+			dst[i+1] = src[i+1]
+			dst[i+2] = src[i]
+			dst[i+3] = src[i+3]
+		}
+	}
+	return frame
+}
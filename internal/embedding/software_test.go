@@ -0,0 +1,86 @@
+package embedding
+
+import (
+	"image"
+	"image/color"
+	"testing"
+)
+
+func TestSoftwareRendererPresent(t *testing.T) {
+	var frames []*image.RGBA
+	var drawnWidth int
+	r := &SoftwareRenderer{
+		Draw: func(buffer []byte, width int, height int, rowBytes int) {
+			drawnWidth = width
+		},
+		FrameCallback: func(frame *image.RGBA) {
+			frames = append(frames, frame)
+		},
+	}
+	r.Resize(2)
+
+	// 2x2 BGRA frame, rows padded to 16 bytes
+	buffer := []byte{
+		0x10, 0x20, 0x30, 0xff, 0x11, 0x21, 0x31, 0x80, 0xaa, 0xaa, 0xaa, 0xaa, 0xaa, 0xaa, 0xaa, 0xaa,
+		0x12, 0x22, 0x32, 0xff, 0x13, 0x23, 0x33, 0x00, 0xaa, 0xaa, 0xaa, 0xaa, 0xaa, 0xaa, 0xaa, 0xaa,
+	}
+	if !r.Present(buffer, 16, 2) {
+		t.Fatal("present failed")
+	}
+	if drawnWidth != 2 {
+		t.Fatalf("drawn width %d, expected 2", drawnWidth)
+	}
+	if len(frames) != 1 {
+		t.Fatalf("%d frames, expected 1", len(frames))
+	}
+	frame := frames[0]
+	if frame.Rect != image.Rect(0, 0, 2, 2) {
+		t.Fatalf("frame bounds %v, expected 2x2", frame.Rect)
+	}
+	expected := map[image.Point]color.RGBA{
+		{0, 0}: {0x30, 0x20, 0x10, 0xff},
+		{1, 0}: {0x31, 0x21, 0x11, 0x80},
+		{0, 1}: {0x32, 0x22, 0x12, 0xff},
+		{1, 1}: {0x33, 0x23, 0x13, 0x00},
+	}
+	for p, c := range expected {
+		if got := frame.RGBAAt(p.X, p.Y); got != c {
+			t.Fatalf("pixel %v is %v, expected %v", p, got, c)
+		}
+	}
+
+	// The frame is a copy, the engine reuses its buffer.
+	buffer[0] = 0
+	if frame.RGBAAt(0, 0).B != 0x10 {
+		t.Fatal("the frame shares the buffer of the engine")
+	}
+}
+
+func TestSoftwareRendererPresentWidth(t *testing.T) {
+	var frameWidth int
+	r := &SoftwareRenderer{
+		FrameCallback: func(frame *image.RGBA) {
+			frameWidth = frame.Rect.Dx()
+		},
+	}
+	buffer := make([]byte, 32)
+
+	// before the first window metrics
+	r.Present(buffer, 16, 2)
+	if frameWidth != 4 {
+		t.Fatalf("frame width %d before the metrics, expected 4", frameWidth)
+	}
+
+	r.Resize(3)
+	r.Present(buffer, 16, 2)
+	if frameWidth != 3 {
+		t.Fatalf("frame width %d, expected 3", frameWidth)
+	}
+
+	// frame rendered with smaller metrics than the current ones
+	r.Resize(8)
+	r.Present(buffer, 16, 2)
+	if frameWidth != 4 {
+		t.Fatalf("frame width %d wider than its rows, expected 4", frameWidth)
+	}
+}
@@ -0,0 +1,8 @@
+//go:build debug
+// +build debug
+
+package messenger
+
+// debugBuild enables the checks that are too costly for release builds.
+// Build with `-tags debug` to enable them.
+const debugBuild = true
@@ -0,0 +1,254 @@
+// Package messenger implements plugin.BinaryMessenger on top of the
+// FlutterEngine, for the glfw and the headless embedders.
+package messenger
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sync"
+
+	"github.com/go-flutter-desktop/go-flutter/embedder"
+	"github.com/go-flutter-desktop/go-flutter/internal/taskqueue"
+	"github.com/go-flutter-desktop/go-flutter/plugin"
+	"github.com/pkg/errors"
+)
+
+// Messenger implements plugin.BinaryMessenger on top of the FlutterEngine.
+type Messenger struct {
+	// Engine must be set before the engine runs.
+	Engine *embedder.FlutterEngine
+	// tasks is the queue of the thread running the engine.
+	tasks *taskqueue.Queue
+
+	channels     map[string]plugin.ChannelHandlerFunc
+	channelsLock sync.RWMutex
+
+	// Unclaimed, when not nil, handles the messages sent on the channels
+	// without handler, instead of an empty reply.
+	Unclaimed func(channel string, binaryMessage []byte, responseSender plugin.ResponseSender) error
+
+	// LogUnhandled enables the logging of the messages left unhandled,
+	// loggedUnhandled holds the channel/method pairs already logged.
+	LogUnhandled    bool
+	loggedUnhandled map[string]struct{}
+
+	// PanicOnReplyMisuse panics instead of logging when a message is
+	// answered twice, or dropped without reply in debug builds.
+	PanicOnReplyMisuse bool
+}
+
+var _ plugin.BinaryMessenger = &Messenger{} // compile-time type check
+
+// New creates a Messenger whose messages are sent by the thread running the
+// tasks.
+func New(tasks *taskqueue.Queue) *Messenger {
+	return &Messenger{
+		tasks:           tasks,
+		channels:        make(map[string]plugin.ChannelHandlerFunc),
+		loggedUnhandled: make(map[string]struct{}),
+	}
+}
+
+// Send pushes a binary message on a channel to the Flutter application.
+// The message is sent by the thread running the engine, Send is safe to call
+// from any goroutine. Messages sent before the FlutterEngine runs are delivered once
+// it is running.
+func (m *Messenger) Send(channel string, binaryMessage []byte) error {
+	msg := &embedder.PlatformMessage{
+		Channel: channel,
+		Data:    binaryMessage,
+	}
+	err := m.tasks.Post(taskqueue.Task{Fn: func() {
+		res := m.Engine.SendPlatformMessage(msg)
+		if res != embedder.KSuccess {
+			log.Printf("failed to send message on channel %s: engine result %d\n", channel, res)
+		}
+	}})
+	if err != nil {
+		return errors.Wrapf(err, "failed to send message on channel %s", channel)
+	}
+	return nil
+}
+
+// SendWithReplyHandler pushes a binary message on a channel to the Flutter
+// application without waiting for its reply. The messages are sent in the
+// order of the calls. replyHandler is called with the reply by the thread
+// running the engine, it isn't called when the message can't be sent. It is
+// called with nil when the engine is shut down before replying.
+func (m *Messenger) SendWithReplyHandler(channel string, binaryMessage []byte, replyHandler func(binaryReply []byte)) error {
+	msg := &embedder.PlatformMessage{
+		Channel: channel,
+		Data:    binaryMessage,
+	}
+	err := m.tasks.Post(taskqueue.Task{Fn: func() {
+		res := m.Engine.SendPlatformMessageWithReply(msg, replyHandler)
+		if res != embedder.KSuccess {
+			log.Printf("failed to send message on channel %s: engine result %d\n", channel, res)
+		}
+	}})
+	if err != nil {
+		return errors.Wrapf(err, "failed to send message on channel %s", channel)
+	}
+	return nil
+}
+
+// SendWithReply pushes a binary message on a channel to the Flutter
+// application and waits for its reply, or for the context to be done.
+// It must not be called from the thread running the engine.
+func (m *Messenger) SendWithReply(ctx context.Context, channel string, binaryMessage []byte) ([]byte, error) {
+	msg := &embedder.PlatformMessage{
+		Channel: channel,
+		Data:    binaryMessage,
+	}
+	// buffered, the engine thread must not block when nobody waits for the
+	// reply anymore
+	replyChan := make(chan []byte, 1)
+	errChan := make(chan error, 1)
+	// done tells whether the task was run, or dropped by the queue stopping
+	// with the engine.
+	done := make(chan bool, 1)
+	err := m.tasks.Post(taskqueue.Task{
+		Fn: func() {
+			res := m.Engine.SendPlatformMessageWithReply(msg, func(binaryReply []byte) {
+				replyChan <- binaryReply
+			})
+			if res != embedder.KSuccess {
+				errChan <- errors.Errorf("engine result %d", res)
+			}
+		},
+		Done: done,
+	})
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to send message on channel %s", channel)
+	}
+	for {
+		select {
+		case binaryReply := <-replyChan:
+			if binaryReply == nil {
+				return nil, errors.Errorf("waiting for the reply on channel %s: the engine stopped", channel)
+			}
+			return binaryReply, nil
+		case err = <-errChan:
+			return nil, errors.Wrapf(err, "failed to send message on channel %s", channel)
+		case sent := <-done:
+			if !sent {
+				return nil, errors.Errorf("failed to send message on channel %s: the engine stopped", channel)
+			}
+			done = nil
+		case <-ctx.Done():
+			return nil, errors.Wrapf(ctx.Err(), "waiting for the reply on channel %s", channel)
+		}
+	}
+}
+
+// SetChannelHandler satisfies plugin.BinaryMessenger
+func (m *Messenger) SetChannelHandler(channel string, channelHandler plugin.ChannelHandlerFunc) {
+	m.channelsLock.Lock()
+	if channelHandler == nil {
+		delete(m.channels, channel)
+	} else {
+		m.channels[channel] = channelHandler
+	}
+	m.channelsLock.Unlock()
+}
+
+// HandlePlatformMessage dispatches a message from the Flutter Engine to the
+// handler of its channel, or to Unclaimed. The handler replies with a
+// responseSender, the reply can be sent after HandlePlatformMessage has
+// returned. It returns false when no handler has claimed the message. It
+// must be called by the thread running the engine.
+func (m *Messenger) HandlePlatformMessage(message *embedder.PlatformMessage) bool {
+	m.channelsLock.RLock()
+	channelHandler, ok := m.channels[message.Channel]
+	m.channelsLock.RUnlock()
+	if !ok {
+		if m.Unclaimed == nil {
+			return false
+		}
+		channelHandler = func(binaryMessage []byte, responseSender plugin.ResponseSender) error {
+			return m.Unclaimed(message.Channel, binaryMessage, responseSender)
+		}
+	}
+
+	responseSender := newResponseSender(m, message)
+	err := channelHandler(message.Data, responseSender)
+	if err != nil {
+		log.Printf("handling message on channel %s: %v\n", message.Channel, err)
+	}
+	responseSender.handlerReturned(err != nil)
+	return true
+}
+
+// respond sends the reply of a handler to a message from the Flutter Engine.
+// It must be called by the thread running the engine.
+func (m *Messenger) respond(message *embedder.PlatformMessage, binaryReply []byte) {
+	// An empty reply to a method call is the not implemented envelope of
+	// method channels. Other messages may legitimately be answered with null.
+	// The message is only decoded when the unhandled messages are logged.
+	if m.LogUnhandled && len(binaryReply) == 0 && decodeMethodName(message.Data) != "" {
+		m.debugUnhandledMessage(message, "method not implemented")
+	}
+	m.sendResponse(message, binaryReply)
+}
+
+// sendResponse sends a reply to a message from the Flutter Engine. It must be
+// called by the thread running the engine.
+func (m *Messenger) sendResponse(message *embedder.PlatformMessage, binaryReply []byte) {
+	if message.ResponseHandle != nil {
+		m.Engine.SendPlatformMessageResponse(message, binaryReply)
+	}
+}
+
+// reportReplyMisuse logs, or panics when enabled with
+// PanicOnReplyMisuse, a message answered twice or never.
+func (m *Messenger) reportReplyMisuse(format string, args ...interface{}) {
+	if m.PanicOnReplyMisuse {
+		panic(fmt.Sprintf("go-flutter: "+format, args...))
+	}
+	log.Printf("go-flutter: "+format+"\n", args...)
+}
+
+// HandleUnclaimedMessage replies with an empty message to a message that no
+// handler or receiver has claimed. The dart side receives null, or a
+// MissingPluginException for method calls, instead of waiting forever. It
+// must be called by the thread running the engine.
+func (m *Messenger) HandleUnclaimedMessage(message *embedder.PlatformMessage) {
+	m.debugUnhandledMessage(message, "no handler")
+	m.sendResponse(message, nil)
+}
+
+// debugUnhandledMessage logs the channel and method of an unhandled
+// message, once per channel/method pair, when enabled with
+// LogUnhandled.
+func (m *Messenger) debugUnhandledMessage(message *embedder.PlatformMessage, reason string) {
+	if !m.LogUnhandled {
+		return
+	}
+	method := decodeMethodName(message.Data)
+	key := message.Channel + "/" + method
+	if _, ok := m.loggedUnhandled[key]; ok {
+		return
+	}
+	m.loggedUnhandled[key] = struct{}{}
+	if method == "" {
+		log.Printf("go-flutter: unhandled message on channel %s (%s)\n", message.Channel, reason)
+		return
+	}
+	log.Printf("go-flutter: unhandled method %s on channel %s (%s)\n", method, message.Channel, reason)
+}
+
+// decodeMethodName returns the name of the method of a method call encoded
+// with the standard or json method codec, an empty string when the message
+// is not a method call.
+func decodeMethodName(binaryMessage []byte) string {
+	methodCall, err := plugin.StandardMethodCodec{}.DecodeMethodCall(binaryMessage)
+	if err == nil {
+		return methodCall.Method
+	}
+	methodCall, err = plugin.JSONMethodCodec{}.DecodeMethodCall(binaryMessage)
+	if err == nil {
+		return methodCall.Method
+	}
+	return ""
+}
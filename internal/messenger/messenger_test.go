@@ -0,0 +1,125 @@
+package messenger
+
+import (
+	"bytes"
+	"context"
+	"log"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-flutter-desktop/go-flutter/embedder"
+	"github.com/go-flutter-desktop/go-flutter/internal/taskqueue"
+	"github.com/go-flutter-desktop/go-flutter/plugin"
+)
+
+func TestSendWithReplyEngineStopped(t *testing.T) {
+	tasks := &taskqueue.Queue{}
+	tasks.Start()
+	m := New(tasks)
+
+	errChan := make(chan error, 1)
+	go func() {
+		_, err := m.SendWithReply(context.Background(), "test/channel", []byte("message"))
+		errChan <- err
+	}()
+	// The queue is never run, the message is dropped when it stops.
+	time.Sleep(10 * time.Millisecond)
+	tasks.Stop()
+
+	select {
+	case err := <-errChan:
+		if err == nil {
+			t.Fatal("SendWithReply succeeded without engine")
+		}
+	case <-time.After(time.Second):
+		t.Fatal("SendWithReply is still waiting once the engine stopped")
+	}
+}
+
+// captureLog returns the output of the standard logger while fn runs.
+func captureLog(fn func()) string {
+	var buf bytes.Buffer
+	log.SetOutput(&buf)
+	defer log.SetOutput(os.Stderr)
+	fn()
+	return buf.String()
+}
+
+// panics tells whether fn panics.
+func panics(fn func()) (panicked bool) {
+	defer func() {
+		panicked = recover() != nil
+	}()
+	fn()
+	return false
+}
+
+// handleMessage dispatches a message to a handler replying once, and
+// returns the responseSender of the message.
+func handleMessage(t *testing.T, m *Messenger) plugin.ResponseSender {
+	var sender plugin.ResponseSender
+	m.SetChannelHandler("test/channel", func(binaryMessage []byte, responseSender plugin.ResponseSender) error {
+		sender = responseSender
+		responseSender.Send([]byte("reply"))
+		return nil
+	})
+	// Without response handle, the reply isn't handed to the engine.
+	if !m.HandlePlatformMessage(&embedder.PlatformMessage{Channel: "test/channel"}) {
+		t.Fatal("message not handled")
+	}
+	return sender
+}
+
+func TestResponseSenderReplyTwice(t *testing.T) {
+	tasks := &taskqueue.Queue{}
+	tasks.Start()
+	defer tasks.Stop()
+
+	m := New(tasks)
+	sender := handleMessage(t, m)
+	output := captureLog(func() {
+		sender.Send([]byte("again"))
+	})
+	if !strings.Contains(output, "reply sent twice") {
+		t.Fatalf("logged %q, expected the second reply to be reported", output)
+	}
+
+	m.PanicOnReplyMisuse = true
+	sender = handleMessage(t, m)
+	if !panics(func() { sender.Send([]byte("again")) }) {
+		t.Fatal("second reply didn't panic with PanicOnReplyMisuse")
+	}
+}
+
+func TestResponseSenderDropped(t *testing.T) {
+	tasks := &taskqueue.Queue{}
+	tasks.Start()
+	defer tasks.Stop()
+
+	m := New(tasks)
+	// The finalizer is only set in debug builds, it is called directly.
+	dropped := newResponseSender(m, &embedder.PlatformMessage{Channel: "test/channel"})
+	dropped.handlerReturned(false)
+	output := captureLog(dropped.finalize)
+	if !strings.Contains(output, "dropped without reply") {
+		t.Fatalf("logged %q, expected the dropped message to be reported", output)
+	}
+	tasks.Run()
+
+	replied := newResponseSender(m, &embedder.PlatformMessage{Channel: "test/channel"})
+	replied.Send(nil)
+	replied.handlerReturned(false)
+	output = captureLog(replied.finalize)
+	if output != "" {
+		t.Fatalf("logged %q for a message with reply", output)
+	}
+
+	m.PanicOnReplyMisuse = true
+	dropped = newResponseSender(m, &embedder.PlatformMessage{Channel: "test/channel"})
+	dropped.handlerReturned(false)
+	if !panics(dropped.finalize) {
+		t.Fatal("dropped message didn't panic with PanicOnReplyMisuse")
+	}
+}
@@ -0,0 +1,106 @@
+package messenger
+
+import (
+	"log"
+	"runtime"
+	"sync"
+
+	"github.com/go-flutter-desktop/go-flutter/embedder"
+	"github.com/go-flutter-desktop/go-flutter/internal/taskqueue"
+	"github.com/go-flutter-desktop/go-flutter/plugin"
+)
+
+// responseSender implements plugin.ResponseSender for a message sent by the
+// FlutterEngine. The reply is sent once, by the thread running the
+// engine.
+//
+// In debug builds, a responseSender garbage collected without reply is
+// reported and answered with an empty reply.
+type responseSender struct {
+	messenger *Messenger
+	message   *embedder.PlatformMessage
+
+	// inHandler is true while the channel handler runs on the engine thread,
+	// a reply given meanwhile is kept in pendingReply and sent when the
+	// handler returns.
+	inHandler    bool
+	pendingReply []byte
+	sent         bool
+	lock         sync.Mutex
+}
+
+var _ plugin.ResponseSender = &responseSender{} // compile-time type check
+
+func newResponseSender(m *Messenger, message *embedder.PlatformMessage) *responseSender {
+	r := &responseSender{
+		messenger: m,
+		message:   message,
+		inHandler: true,
+	}
+	if debugBuild && message.ResponseHandle != nil {
+		runtime.SetFinalizer(r, (*responseSender).finalize)
+	}
+	return r
+}
+
+// Send satisfies plugin.ResponseSender, it is safe to call from any
+// goroutine.
+func (r *responseSender) Send(binaryReply []byte) {
+	r.lock.Lock()
+	if r.sent {
+		r.lock.Unlock()
+		r.messenger.reportReplyMisuse("reply sent twice to the message on channel %s", r.message.Channel)
+		return
+	}
+	r.sent = true
+	if r.inHandler {
+		r.pendingReply = binaryReply
+		r.lock.Unlock()
+		return
+	}
+	r.lock.Unlock()
+
+	err := r.messenger.tasks.Post(taskqueue.Task{Fn: func() {
+		r.messenger.respond(r.message, binaryReply)
+	}})
+	if err != nil {
+		log.Printf("failed to reply to the message on channel %s: %v\n", r.message.Channel, err)
+	}
+}
+
+// handlerReturned sends the reply given while the handler was running. When
+// the handler has failed without replying, an empty reply is sent. It must be
+// called by the thread running the engine.
+func (r *responseSender) handlerReturned(failed bool) {
+	r.lock.Lock()
+	r.inHandler = false
+	failedWithoutReply := failed && !r.sent
+	r.sent = r.sent || failed
+	sent, binaryReply := r.sent, r.pendingReply
+	r.pendingReply = nil
+	r.lock.Unlock()
+
+	if failedWithoutReply {
+		// the error of the handler has already been logged
+		r.messenger.sendResponse(r.message, nil)
+		return
+	}
+	if sent {
+		r.messenger.respond(r.message, binaryReply)
+	}
+}
+
+// finalize reports a responseSender dropped without reply and answers the
+// message, the dart side would otherwise wait forever.
+func (r *responseSender) finalize() {
+	if r.sent {
+		return
+	}
+	r.messenger.reportReplyMisuse("message on channel %s dropped without reply", r.message.Channel)
+	err := r.messenger.tasks.Post(taskqueue.Task{Fn: func() {
+		r.messenger.sendResponse(r.message, nil)
+	}})
+	if err != nil {
+		log.Printf("failed to reply to the message on channel %s: %v\n", r.message.Channel, err)
+	}
+}
@@ -0,0 +1,65 @@
+// Package systemchannels holds the wire format of the flutter system channels
+// spoken by the glfw embedder, see system_channels.dart. The fluttertest
+// package sends the same messages.
+package systemchannels
+
+import (
+	"github.com/go-flutter-desktop/go-flutter/plugin"
+)
+
+// textinput method calls
+const (
+	// TextInputChannel is the channel of the text fields.
+	TextInputChannel = "flutter/textinput"
+
+	// Args -> struct EditingState
+	TextUpdateStateMethod = "TextInputClient.updateEditingState"
+	TextPerformAction     = "TextInputClient.performAction"
+
+	TextInputClientSet    = "TextInput.setClient"
+	TextInputClientClear  = "TextInput.clearClient"
+	TextInputSetEditState = "TextInput.setEditingState"
+	TextInputShow         = "TextInput.show"
+	TextInputHide         = "TextInput.hide"
+)
+
+// EditingState is the state of a text field, the selection offsets are
+// counted in characters.
+type EditingState struct {
+	Text                   string `json:"text"`
+	SelectionBase          int    `json:"selectionBase"`
+	SelectionExtent        int    `json:"selectionExtent"`
+	SelectionAffinity      string `json:"selectionAffinity"`
+	SelectionIsDirectional bool   `json:"selectionIsDirectional"`
+	ComposingBase          int    `json:"composingBase"`
+	ComposingExtent        int    `json:"composingExtent"`
+}
+
+// keyevent messages
+const (
+	// KeyEventChannel is the channel of the raw key events.
+	KeyEventChannel = "flutter/keyevent"
+
+	KeyEventDown = "keydown"
+	KeyEventUp   = "keyup"
+)
+
+// KeyEvent is the message of a raw key event, in the format of the glfw
+// keymap of RawKeyEventDataLinux.
+type KeyEvent struct {
+	Type                string `json:"type"`
+	Keymap              string `json:"keymap"`
+	Toolkit             string `json:"toolkit"`
+	KeyCode             int    `json:"keyCode"`
+	ScanCode            int    `json:"scanCode"`
+	Modifiers           int    `json:"modifiers"`
+	UnicodeScalarValues rune   `json:"unicodeScalarValues"`
+}
+
+// EncodeKeyEvent encodes a key event in the glfw keymap, the key code, scan
+// code and modifiers are glfw values.
+func EncodeKeyEvent(event KeyEvent) ([]byte, error) {
+	event.Keymap = "linux"
+	event.Toolkit = "glfw"
+	return plugin.JSONMessageCodec{}.EncodeMessage(event)
+}
@@ -0,0 +1,74 @@
+// Package taskqueue implements the queue of the tasks that other goroutines
+// hand to the thread running the FlutterEngine.
+package taskqueue
+
+import (
+	"sync"
+
+	"github.com/pkg/errors"
+)
+
+// Task is a func waiting to be executed by the thread running the engine.
+type Task struct {
+	Fn   func()
+	Done chan bool // receives true once Fn has returned, false when dropped
+}
+
+// Queue holds the tasks posted from other goroutines until the thread
+// running the engine executes them.
+type Queue struct {
+	running bool
+	tasks   []Task
+	lock    sync.Mutex
+}
+
+// Start accepts new tasks, they will be executed once the loop of the thread
+// running the engine starts.
+func (q *Queue) Start() {
+	q.lock.Lock()
+	q.running = true
+	q.lock.Unlock()
+}
+
+// Stop refuses new tasks and drops the pending ones.
+func (q *Queue) Stop() {
+	q.lock.Lock()
+	q.running = false
+	tasks := q.tasks
+	q.tasks = nil
+	q.lock.Unlock()
+
+	for _, task := range tasks {
+		if task.Done != nil {
+			task.Done <- false
+		}
+	}
+}
+
+// Post adds a task to the queue, it is safe to call from any goroutine.
+// Tasks are executed in the order they are posted.
+func (q *Queue) Post(task Task) error {
+	q.lock.Lock()
+	defer q.lock.Unlock()
+	if !q.running {
+		return errors.New("the application is not running")
+	}
+	q.tasks = append(q.tasks, task)
+	return nil
+}
+
+// Run executes the pending tasks, it must be called by the thread running
+// the engine. Tasks posted while running are executed by the next call.
+func (q *Queue) Run() {
+	q.lock.Lock()
+	tasks := q.tasks
+	q.tasks = nil
+	q.lock.Unlock()
+
+	for _, task := range tasks {
+		task.Fn()
+		if task.Done != nil {
+			task.Done <- true
+		}
+	}
+}
@@ -0,0 +1,39 @@
+package flutter
+
+import (
+	"github.com/go-flutter-desktop/go-flutter/internal/taskqueue"
+	"github.com/pkg/errors"
+)
+
+// mainThreadTasks is the queue serviced by the main loop of `Run`. Only one
+// application can run per process, the main thread being unique.
+var mainThreadTasks = &taskqueue.Queue{}
+
+// PostMainThreadTask schedules task to be executed by the main thread, which
+// owns the window and the FlutterEngine. It is safe to call from any
+// goroutine, tasks are executed in the order they are posted.
+//
+// Tasks can be posted once `Run` has been called and until the window is
+// closed, otherwise an error is returned. Tasks still pending when the window
+// closes are dropped.
+func PostMainThreadTask(task func()) error {
+	return mainThreadTasks.Post(taskqueue.Task{Fn: task})
+}
+
+// RunOnMainThread executes task on the main thread and waits for it to
+// complete. An error is returned when the task could not be executed, see
+// PostMainThreadTask.
+//
+// RunOnMainThread must not be called from the main thread, e.g.: in a plugin
+// hook or in a channel handler, it would block forever.
+func RunOnMainThread(task func()) error {
+	done := make(chan bool, 1)
+	err := mainThreadTasks.Post(taskqueue.Task{Fn: task, Done: done})
+	if err != nil {
+		return err
+	}
+	if !<-done {
+		return errors.New("the application stopped before the task was executed")
+	}
+	return nil
+}
@@ -3,24 +3,27 @@ package flutter
 import (
 	"fmt"
 	"image"
-	"os"
 
+	"github.com/go-flutter-desktop/go-flutter/internal/embedding"
 	"github.com/go-gl/glfw/v3.2/glfw"
 )
 
 type config struct {
-	WindowDimension struct {
-		x int
-		y int
-	}
-	AssetsPath                  string
-	ICUDataPath                 string
+	embedding.Config
+
 	WindowInitializerDeprecated func(*glfw.Window) error
 	WindowIconProvider          func() ([]image.Image, error)
 	ForcePixelRatio             float64
 	VMArguments                 []string
 	PlatformMessageReceivers    map[string][]PluginReceivers // The Key is the Channel name.
+	Plugins                     []Plugin
+	SoftwareRenderer            bool
+	DisableResourceContext      bool
+	SurfaceTransformation       *SurfaceTransformation
+	FBOTarget                   *FBOTarget
+	SoftwareFrameCallback       func(frame *image.RGBA)
 	KeyboardLayout              *KeyboardShortcuts
+	ScrollAmount                float64
 }
 
 func (c config) merge(options ...Option) config {
@@ -31,9 +34,37 @@ func (c config) merge(options ...Option) config {
 	return c
 }
 
+// validate returns the errors of the options shared with the headless
+// package, see embedding.Config.Validate, or an error when options can't be
+// used together.
+func (c config) validate() error {
+	err := c.Config.Validate()
+	if err != nil {
+		return err
+	}
+	if c.SurfaceTransformation != nil && c.SurfaceTransformation.Matrix == nil {
+		return &InvalidOptionError{Option: "OptionSurfaceTransformation", Missing: []string{"Matrix"}}
+	}
+	if c.SoftwareRenderer && c.SurfaceTransformation != nil {
+		return &IncompatibleOptionsError{Option: "OptionSurfaceTransformation", OtherOption: "OptionSoftwareRenderer"}
+	}
+	if c.SoftwareRenderer && c.FBOTarget != nil {
+		return &IncompatibleOptionsError{Option: "OptionFBOTarget", OtherOption: "OptionSoftwareRenderer"}
+	}
+	return nil
+}
+
 // Option for gutter
 type Option func(*config)
 
+// sharedOption wraps an option of the config shared with the headless
+// package.
+func sharedOption(option embedding.Option) Option {
+	return func(c *config) {
+		option(&c.Config)
+	}
+}
+
 // ProjectAssetPath specify the flutter assets directory.
 func ProjectAssetPath(p string) Option {
 	// deprecated on 2019-03-05
@@ -42,55 +73,27 @@ func ProjectAssetPath(p string) Option {
 }
 
 // ProjectAssetsPath specify the flutter assets directory.
+// An invalid directory is reported by Run as an *InvalidAssetsPathError.
 func ProjectAssetsPath(p string) Option {
-	_, err := os.Stat(p)
-	if err != nil {
-		fmt.Printf("Failed to stat asset path: %v\n", err)
-		os.Exit(1)
-	}
-	return func(c *config) {
-		c.AssetsPath = p
-	}
+	return sharedOption(embedding.ProjectAssetsPath(p))
 }
 
 // ApplicationICUDataPath specify the path to the ICUData.
+// A missing file is reported by Run as a *MissingICUDataError.
 func ApplicationICUDataPath(p string) Option {
-	_, err := os.Stat(p)
-	if err != nil {
-		fmt.Printf("Failed to stat icu data path: %v\n", err)
-		os.Exit(1)
-	}
-	return func(c *config) {
-		c.ICUDataPath = p
-	}
+	return sharedOption(embedding.ApplicationICUDataPath(p))
 }
 
 // OptionVMArguments specify the arguments to the Dart VM.
 func OptionVMArguments(a []string) Option {
-	return func(c *config) {
-		// First should be argument is argv[0]
-		c.VMArguments = append([]string{""}, a...)
-	}
+	return sharedOption(embedding.OptionVMArguments(a))
 }
 
 // ApplicationWindowDimension specify the startup's dimention of the window.
+// A dimension lower than 1 is reported by Run as an
+// *InvalidWindowDimensionError.
 func ApplicationWindowDimension(x int, y int) Option {
-	// Check for initial application display size
-	if x < 1 {
-		fmt.Println("Wrong initial value for width ")
-		os.Exit(1)
-	}
-
-	// Check for initial application display size
-	if y < 1 {
-		fmt.Println("Wrong initial value for height ")
-		os.Exit(1)
-	}
-
-	return func(c *config) {
-		c.WindowDimension.x = x
-		c.WindowDimension.y = y
-	}
+	return sharedOption(embedding.ApplicationWindowDimension(x, y))
 }
 
 // OptionWindowInitializer allow initializing the window.
@@ -119,9 +122,88 @@ func OptionPixelRatio(ratio float64) Option {
 	}
 }
 
+// OptionSoftwareRenderer renders the flutter application with the software
+// renderer of the engine, for machines without GPU. The frames are drawn in
+// the window with a few legacy OpenGL calls, which software OpenGL
+// implementations (e.g.: Mesa llvmpipe) support.
+//
+// When not nil, frameCallback receives every frame after it has been drawn.
+// It is called by the render thread of the engine, the frame can be kept.
+// Applications started by the headless package always use the software
+// renderer. External textures are not supported, TextureRegistry.Register
+// returns an error.
+func OptionSoftwareRenderer(frameCallback func(frame *image.RGBA)) Option {
+	return func(c *config) {
+		c.SoftwareRenderer = true
+		c.SoftwareFrameCallback = frameCallback
+	}
+}
+
+// OptionDisableResourceContext disables the hidden window whose OpenGL
+// context is used by the engine to upload textures in the background. The
+// textures are then uploaded by the render thread. Useful on drivers that
+// misbehave with shared contexts.
+func OptionDisableResourceContext() Option {
+	return func(c *config) {
+		c.DisableResourceContext = true
+	}
+}
+
+// OptionSurfaceTransformation transforms the flutter surface drawn in the
+// window, e.g.: `flutter.SurfaceRotate90` for a portrait mounted display.
+// The pointer events are transformed to match. Run returns an
+// *InvalidOptionError when the Matrix of the transformation is nil, and an
+// *IncompatibleOptionsError when it is used with OptionSoftwareRenderer.
+func OptionSurfaceTransformation(transformation SurfaceTransformation) Option {
+	return func(c *config) {
+		c.SurfaceTransformation = &transformation
+	}
+}
+
+// FBOTarget describes the framebuffer object the flutter application is
+// rendered into, instead of the default framebuffer of the window. The
+// callbacks are called by the render thread, with the OpenGL context of the
+// window current.
+type FBOTarget struct {
+	// FBO returns the framebuffer object to render into. It is called once,
+	// or before every frame when ResetAfterPresent is set.
+	FBO func() uint32
+	// ResetAfterPresent asks FBO again after every frame, e.g.: when the
+	// host swaps between several FBOs.
+	ResetAfterPresent bool
+	// PostPresent is called once a frame has been rendered into the FBO.
+	// go-flutter doesn't swap the buffers of the window: the host composites
+	// its scene and the FBO, and presents them, there. Optional.
+	PostPresent func()
+}
+
+// OptionFBOTarget renders the flutter application into a framebuffer object
+// supplied by the host, to embed the flutter UI in the OpenGL scene drawn in
+// the glfw window. Run returns an *IncompatibleOptionsError when it is used
+// with OptionSoftwareRenderer.
+//
+// Hosts owning their OpenGL context and their main loop use
+// headless.OptionOpenGLRenderer and headless.RunHostDriven instead.
+func OptionFBOTarget(target FBOTarget) Option {
+	return func(c *config) {
+		c.FBOTarget = &target
+	}
+}
+
+// AddPlugin adds a plugin to the flutter application.
+func AddPlugin(p Plugin) Option {
+	return func(c *config) {
+		c.Plugins = append(c.Plugins, p)
+	}
+}
+
 // OptionAddPluginReceiver add a new function that will be trigger
 // when the FlutterEngine send a PlatformMessage to the Embedder
+//
+// Deprecated: use AddPlugin, raw messages are received by registering a
+// channel handler on the BinaryMessenger given to the plugin.
 func OptionAddPluginReceiver(handler PluginReceivers, channelName string) Option {
+	fmt.Println("OptionAddPluginReceiver is deprecated, use AddPlugin and register a channel handler on the BinaryMessenger.")
 	return func(c *config) {
 		// Check for nil, else initialise the map
 		if c.PlatformMessageReceivers == nil {
@@ -132,6 +214,13 @@ func OptionAddPluginReceiver(handler PluginReceivers, channelName string) Option
 	}
 }
 
+// OptionLogUnhandledMessages logs the channel and method of the messages sent
+// by the FlutterEngine that no plugin has handled. Each channel/method pair is
+// logged once. Useful to find missing plugins during development.
+func OptionLogUnhandledMessages() Option {
+	return sharedOption(embedding.OptionLogUnhandledMessages())
+}
+
 // OptionKeyboardLayout allow application to support keyboard that have a different layout
 // when the FlutterEngine send a PlatformMessage to the Embedder
 func OptionKeyboardLayout(keyboardLayout KeyboardShortcuts) Option {
@@ -148,3 +237,36 @@ type KeyboardShortcuts struct {
 	Paste     glfw.Key
 	SelectAll glfw.Key
 }
+
+// OptionPanicOnReplyMisuse panics, instead of logging, when a plugin answers
+// a message twice. In debug builds (`-tags debug`) it also panics when a
+// plugin drops a message without reply.
+func OptionPanicOnReplyMisuse() Option {
+	return sharedOption(embedding.OptionPanicOnReplyMisuse())
+}
+
+// OptionAOTSnapshotFiles runs an application compiled ahead of time, as
+// required by release builds of the engine. The snapshot files are
+// memory-mapped by the engine, a file that can't be mapped is reported by
+// Run as an *AOTSnapshotError.
+func OptionAOTSnapshotFiles(vmData, vmInstructions, isolateData, isolateInstructions string) Option {
+	return sharedOption(embedding.OptionAOTSnapshotFiles(vmData, vmInstructions, isolateData, isolateInstructions))
+}
+
+// OptionAOTSnapshotBuffers runs an application compiled ahead of time from
+// in-memory snapshots, for instance embedded in the executable. The buffers
+// are copied to memory pages that can be executed. A buffer that can't be
+// copied is reported by Run as an *AOTSnapshotError.
+func OptionAOTSnapshotBuffers(vmData, vmInstructions, isolateData, isolateInstructions []byte) Option {
+	return sharedOption(embedding.OptionAOTSnapshotBuffers(vmData, vmInstructions, isolateData, isolateInstructions))
+}
+
+// OptionScrollAmount sets the distance scrolled for a line of scroll offset,
+// e.g.: a notch of the mouse wheel, in screen coordinates. The smooth
+// scrolls of trackpads send fractions of lines. A negative amount inverts the
+// scroll direction. Defaults to 100.
+func OptionScrollAmount(amount float64) Option {
+	return func(c *config) {
+		c.ScrollAmount = amount
+	}
+}
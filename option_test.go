@@ -0,0 +1,216 @@
+package flutter
+
+import (
+	"archive/zip"
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/pkg/errors"
+)
+
+// testProjectOptions returns the options of a project made of an empty
+// assets directory and ICU data file, and a func removing them.
+func testProjectOptions(t *testing.T) ([]Option, func()) {
+	t.Helper()
+	dir, err := ioutil.TempDir("", "go-flutter-test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	cleanup := func() { os.RemoveAll(dir) }
+	assetsPath := filepath.Join(dir, "flutter_assets")
+	icuDataPath := filepath.Join(dir, "icudtl.dat")
+	err = os.Mkdir(assetsPath, 0755)
+	if err == nil {
+		err = ioutil.WriteFile(icuDataPath, nil, 0644)
+	}
+	if err != nil {
+		cleanup()
+		t.Fatal(err)
+	}
+	return []Option{ProjectAssetsPath(assetsPath), ApplicationICUDataPath(icuDataPath)}, cleanup
+}
+
+func TestValidateIncompatibleOptions(t *testing.T) {
+	projectOptions, cleanup := testProjectOptions(t)
+	defer cleanup()
+
+	tests := []struct {
+		name    string
+		options []Option
+		option  string
+	}{
+		{
+			name:    "surface transformation with software renderer",
+			options: []Option{OptionSurfaceTransformation(SurfaceRotate90), OptionSoftwareRenderer(nil)},
+			option:  "OptionSurfaceTransformation",
+		},
+		{
+			name:    "FBO target with software renderer",
+			options: []Option{OptionSoftwareRenderer(nil), OptionFBOTarget(FBOTarget{})},
+			option:  "OptionFBOTarget",
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var c config
+			c = c.merge(append(projectOptions, test.options...)...)
+			err := c.validate()
+			incompatibleErr, ok := errors.Cause(err).(*IncompatibleOptionsError)
+			if !ok {
+				t.Fatalf("validate returned %v, expected an *IncompatibleOptionsError", err)
+			}
+			if incompatibleErr.Option != test.option {
+				t.Fatalf("incompatible option %s, expected %s", incompatibleErr.Option, test.option)
+			}
+		})
+	}
+
+	var c config
+	c = c.merge(append(projectOptions, OptionSurfaceTransformation(SurfaceRotate90))...)
+	err := c.validate()
+	if err != nil {
+		t.Fatalf("validate returned %v for a surface transformation alone", err)
+	}
+}
+
+func TestValidateLastOptionWins(t *testing.T) {
+	projectOptions, cleanup := testProjectOptions(t)
+	defer cleanup()
+
+	var c config
+	c = c.merge(append([]Option{ProjectAssetsPath("/nonexistent/flutter_assets")}, projectOptions...)...)
+	c = c.merge(ApplicationWindowDimension(0, 0), ApplicationWindowDimension(800, 600))
+	err := c.validate()
+	if err != nil {
+		t.Fatalf("validate returned %v, the valid options were set last", err)
+	}
+
+	c = config{}
+	c = c.merge(append(projectOptions, ApplicationWindowDimension(800, 600), ApplicationWindowDimension(0, 600))...)
+	err = c.validate()
+	if _, ok := errors.Cause(err).(*InvalidWindowDimensionError); !ok {
+		t.Fatalf("validate returned %v, expected an *InvalidWindowDimensionError", err)
+	}
+
+	c = config{}
+	c = c.merge(append(projectOptions, ProjectAssetsPath("/nonexistent/flutter_assets"))...)
+	err = c.validate()
+	if _, ok := errors.Cause(err).(*InvalidAssetsPathError); !ok {
+		t.Fatalf("validate returned %v, expected an *InvalidAssetsPathError", err)
+	}
+}
+
+func TestValidateSurfaceTransformationWithoutMatrix(t *testing.T) {
+	projectOptions, cleanup := testProjectOptions(t)
+	defer cleanup()
+
+	var c config
+	c = c.merge(append(projectOptions, OptionSurfaceTransformation(SurfaceTransformation{SwapAxes: true}))...)
+	err := c.validate()
+	invalidErr, ok := errors.Cause(err).(*InvalidOptionError)
+	if !ok {
+		t.Fatalf("validate returned %v, expected an *InvalidOptionError", err)
+	}
+	if invalidErr.Option != "OptionSurfaceTransformation" || len(invalidErr.Missing) != 1 || invalidErr.Missing[0] != "Matrix" {
+		t.Fatalf("invalid option %s missing %v, expected OptionSurfaceTransformation missing [Matrix]", invalidErr.Option, invalidErr.Missing)
+	}
+}
+
+// testBundleArchive returns a bundle archive holding an empty assets
+// directory and ICU data file. The user cache directory, where the archive is
+// extracted, is moved to a temporary directory until the returned func is
+// called.
+func testBundleArchive(t *testing.T) ([]byte, func()) {
+	t.Helper()
+	var buf bytes.Buffer
+	w := zip.NewWriter(&buf)
+	for _, name := range []string{"flutter_assets/AssetManifest.json", "icudtl.dat"} {
+		_, err := w.Create(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	err := w.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cacheDir, err := ioutil.TempDir("", "go-flutter-test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	oldCacheHome, oldHome := os.Getenv("XDG_CACHE_HOME"), os.Getenv("HOME")
+	os.Setenv("XDG_CACHE_HOME", cacheDir)
+	os.Setenv("HOME", cacheDir)
+	return buf.Bytes(), func() {
+		os.Setenv("XDG_CACHE_HOME", oldCacheHome)
+		os.Setenv("HOME", oldHome)
+		os.RemoveAll(cacheDir)
+	}
+}
+
+func TestValidateBundleReplacesPaths(t *testing.T) {
+	archive, cleanup := testBundleArchive(t)
+	defer cleanup()
+
+	var c config
+	c = c.merge(
+		ProjectAssetsPath("/nonexistent/flutter_assets"),
+		ApplicationICUDataPath("/nonexistent/icudtl.dat"),
+		ApplicationWindowDimension(800, 600),
+		OptionBundle(archive),
+	)
+	err := c.ExtractBundle()
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = c.validate()
+	if err != nil {
+		t.Fatalf("validate returned %v, the bundle replaces the invalid paths", err)
+	}
+	if _, err = os.Stat(filepath.Join(c.AssetsPath, "AssetManifest.json")); err != nil {
+		t.Fatalf("assets path %s is not the extracted bundle: %v", c.AssetsPath, err)
+	}
+	if _, err = os.Stat(c.ICUDataPath); err != nil {
+		t.Fatalf("ICU data path %s is not the extracted bundle: %v", c.ICUDataPath, err)
+	}
+}
+
+// countingSwapper counts the buffer swaps of a window.
+type countingSwapper struct {
+	swaps int
+}
+
+func (w *countingSwapper) SwapBuffers() {
+	w.swaps++
+}
+
+func TestPresentCallback(t *testing.T) {
+	window := &countingSwapper{}
+	present := newPresentCallback(window, nil)
+	if !present() || window.swaps != 1 {
+		t.Fatalf("%d swaps without FBO target, expected 1", window.swaps)
+	}
+
+	window = &countingSwapper{}
+	var postPresents int
+	present = newPresentCallback(window, &FBOTarget{PostPresent: func() { postPresents++ }})
+	if !present() || !present() {
+		t.Fatal("present failed")
+	}
+	if window.swaps != 0 {
+		t.Fatalf("%d swaps with an FBO target, expected none", window.swaps)
+	}
+	if postPresents != 2 {
+		t.Fatalf("%d post presents, expected 2", postPresents)
+	}
+
+	window = &countingSwapper{}
+	present = newPresentCallback(window, &FBOTarget{})
+	if !present() || window.swaps != 0 {
+		t.Fatalf("%d swaps with an FBO target without PostPresent, expected none", window.swaps)
+	}
+}
@@ -0,0 +1,68 @@
+package flutter
+
+import (
+	"github.com/go-flutter-desktop/go-flutter/plugin"
+	"github.com/go-gl/glfw/v3.2/glfw"
+)
+
+// Plugin defines the interface that each plugin must implement.
+// When a plugin also implements PluginGLFW, PluginWindow, PluginTexture or
+// PluginCloser, the corresponding hooks are called during the lifetime of the application.
+//
+// Plugins only using the plugin package, with PluginWindow instead of
+// PluginGLFW, can be tested with the fakes of the `plugin/plugintest`
+// package, without the flutter engine.
+type Plugin interface {
+	// InitPlugin is called before the window is created. The messenger is
+	// used to register the channels of the plugin, e.g.: with
+	// `plugin.NewMethodChannel`, and can be kept to push messages to the
+	// flutter application once the engine is running.
+	InitPlugin(messenger plugin.BinaryMessenger) error
+}
+
+// PluginGLFW defines the interface for plugins that need access to the
+// window.
+type PluginGLFW interface {
+	// Any PluginGLFW must also adhere to the Plugin interface.
+	Plugin
+
+	// InitPluginGLFW is called after the window is created and the
+	// FlutterEngine is running. The window can be kept by the plugin.
+	InitPluginGLFW(window *glfw.Window) error
+}
+
+// PluginWindow defines the interface for plugins that need the window
+// operations of plugin.Window.
+type PluginWindow interface {
+	// Any PluginWindow must also adhere to the Plugin interface.
+	Plugin
+
+	// InitPluginWindow is called after the window is created and the
+	// FlutterEngine is running. The window can be kept by the plugin.
+	InitPluginWindow(window plugin.Window) error
+}
+
+var _ plugin.Window = &glfw.Window{} // compile-time type check
+
+// PluginTexture defines the interface for plugins that display external
+// textures, e.g.: video or camera frames.
+type PluginTexture interface {
+	// Any PluginTexture must also adhere to the Plugin interface.
+	Plugin
+
+	// InitPluginTexture is called after the window is created and the
+	// FlutterEngine is running. The registry can be kept by the plugin.
+	InitPluginTexture(registry *TextureRegistry) error
+}
+
+// PluginCloser defines the interface for plugins that need to clean up
+// when the application stops.
+type PluginCloser interface {
+	// Any PluginCloser must also adhere to the Plugin interface.
+	Plugin
+
+	// ClosePlugin is called once the window is closed, before the
+	// FlutterEngine is shut down. Messages can't be sent anymore. It is also
+	// called when Run fails after InitPlugin.
+	ClosePlugin() error
+}
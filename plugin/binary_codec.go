@@ -0,0 +1,26 @@
+package plugin
+
+import "github.com/pkg/errors"
+
+// BinaryCodec implements a MessageCodec using unencoded binary messages,
+// represented as byte slices.
+type BinaryCodec struct{}
+
+var _ MessageCodec = BinaryCodec{} // compile-time type check
+
+// EncodeMessage returns message unchanged, message must be a []byte.
+func (BinaryCodec) EncodeMessage(message interface{}) ([]byte, error) {
+	if message == nil {
+		return nil, nil
+	}
+	binaryMessage, ok := message.([]byte)
+	if !ok {
+		return nil, errors.Errorf("binary codec: expected []byte, got %T", message)
+	}
+	return binaryMessage, nil
+}
+
+// DecodeMessage returns the binary message unchanged, as a []byte.
+func (BinaryCodec) DecodeMessage(binaryMessage []byte) (interface{}, error) {
+	return binaryMessage, nil
+}
@@ -0,0 +1,44 @@
+package plugin
+
+import "context"
+
+// BinaryMessenger defines a bidirectional binary messenger, used by the
+// channels of this package to talk to the Flutter application.
+//
+// Implementations must be safe for concurrent use: messages can be sent from
+// any goroutine.
+type BinaryMessenger interface {
+	// Send sends a binary message to the Flutter application.
+	Send(channel string, binaryMessage []byte) error
+
+	// SendWithReply sends a binary message to the Flutter application and
+	// waits for its reply. It returns early with the error of the context
+	// when the context is done before the reply is received.
+	//
+	// The reply is delivered by the thread running the engine, SendWithReply
+	// must not be called from that thread, e.g.: in a ChannelHandlerFunc.
+	SendWithReply(ctx context.Context, channel string, binaryMessage []byte) (binaryReply []byte, err error)
+
+	// SetChannelHandler registers a handler to be invoked when the Flutter
+	// application sends a message to its host platform on given channel.
+	// A nil handler removes the handler of the channel.
+	SetChannelHandler(channel string, handler ChannelHandlerFunc)
+}
+
+// ChannelHandlerFunc describes the function that handles binary messages
+// sent on a channel. The reply is sent back to the Flutter application with
+// the ResponseSender, synchronously or later from any goroutine. A returned
+// error is logged and answered with an empty reply when the handler has not
+// replied yet.
+type ChannelHandlerFunc func(binaryMessage []byte, responseSender ResponseSender) (err error)
+
+// ResponseSender is the reply handle of a message sent by the Flutter
+// application. Every message must be answered exactly once, the dart side
+// waits for the reply until it is sent.
+//
+// Implementations must be safe for concurrent use.
+type ResponseSender interface {
+	// Send sends the reply to the Flutter application, a nil binaryReply is
+	// received as null. Replies after the first one are dropped.
+	Send(binaryReply []byte)
+}
@@ -0,0 +1,53 @@
+// Package plugin contains the types needed to communicate with the Dart side
+// of a Flutter application over platform channels.
+//
+// The codecs in this package are Go ports of the codecs defined in
+// https://github.com/flutter/flutter/blob/master/packages/flutter/lib/src/services/message_codecs.dart
+package plugin
+
+import "fmt"
+
+// MessageCodec defines a message encoding/decoding mechanism.
+type MessageCodec interface {
+	// EncodeMessage encodes a message to a slice of bytes.
+	EncodeMessage(message interface{}) (binaryMessage []byte, err error)
+	// DecodeMessage decodes a slice of bytes to a message.
+	DecodeMessage(binaryMessage []byte) (message interface{}, err error)
+}
+
+// MethodCall describes a method invocation.
+type MethodCall struct {
+	// Method is the name of the method being invoked.
+	Method string
+	// Arguments holds the arguments of the invocation, in the representation
+	// used by the codec that decoded the call.
+	Arguments interface{}
+}
+
+// MethodCodec describes a codec for method calls and enveloped results.
+type MethodCodec interface {
+	// EncodeMethodCall encodes the MethodCall into binary.
+	EncodeMethodCall(methodCall MethodCall) (data []byte, err error)
+	// DecodeMethodCall decodes the MethodCall from binary.
+	DecodeMethodCall(data []byte) (methodCall MethodCall, err error)
+	// EncodeSuccessEnvelope encodes a successful result into a binary
+	// envelope.
+	EncodeSuccessEnvelope(result interface{}) (data []byte, err error)
+	// EncodeErrorEnvelope encodes an error result into a binary envelope.
+	EncodeErrorEnvelope(code string, message string, details interface{}) (data []byte, err error)
+	// DecodeEnvelope decodes a result envelope from binary. When the envelope
+	// holds an error, a *FlutterError is returned.
+	DecodeEnvelope(envelope []byte) (result interface{}, err error)
+}
+
+// FlutterError is the error carried by an error envelope.
+type FlutterError struct {
+	Code    string
+	Message string
+	Details interface{}
+}
+
+// Error implements the error interface.
+func (e *FlutterError) Error() string {
+	return fmt.Sprintf("Error %s: %s, details: %v", e.Code, e.Message, e.Details)
+}
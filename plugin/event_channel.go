@@ -0,0 +1,193 @@
+package plugin
+
+import (
+	"log"
+	"sync"
+
+	"github.com/pkg/errors"
+)
+
+// EventChannel provides a way for flutter applications and hosts to
+// communicate using event streams.
+// It must be used with a codec, for example the StandardMethodCodec.
+//
+// The dart side listens to the stream with
+// `EventChannel.receiveBroadcastStream`, the events are pushed by the Go side
+// with the EventSink given to the StreamHandler of the channel.
+type EventChannel struct {
+	messenger   BinaryMessenger
+	channelName string
+	methodCodec MethodCodec
+
+	handler     StreamHandler
+	activeSink  *EventSink
+	handlerLock sync.Mutex
+}
+
+// NewEventChannel creates a new event channel and registers it on the
+// messenger.
+func NewEventChannel(messenger BinaryMessenger, channelName string, methodCodec MethodCodec) *EventChannel {
+	ec := &EventChannel{
+		messenger:   messenger,
+		channelName: channelName,
+		methodCodec: methodCodec,
+	}
+	messenger.SetChannelHandler(channelName, ec.handleChannelMessage)
+	return ec
+}
+
+// Handle registers a StreamHandler for the event channel.
+//
+// Consecutive calls override any existing handler registration, the stream
+// of the previous handler is not cancelled. A nil handler removes the
+// handler, listening is then answered as not implemented. It may be called
+// by the StreamHandler, from OnListen or OnCancel.
+func (e *EventChannel) Handle(handler StreamHandler) {
+	e.handlerLock.Lock()
+	e.handler = handler
+	e.handlerLock.Unlock()
+}
+
+// handleChannelMessage answers the `listen` and `cancel` method calls of the
+// dart side.
+func (e *EventChannel) handleChannelMessage(binaryMessage []byte, responseSender ResponseSender) error {
+	binaryReply, err := e.handleMethodCall(binaryMessage)
+	if err != nil {
+		return err
+	}
+	responseSender.Send(binaryReply)
+	return nil
+}
+
+// handleMethodCall decodes the `listen` and `cancel` method calls of the dart
+// side, calls the handler, and encodes the reply. The handler is called
+// without holding handlerLock, it may call Handle.
+func (e *EventChannel) handleMethodCall(binaryMessage []byte) ([]byte, error) {
+	methodCall, err := e.methodCodec.DecodeMethodCall(binaryMessage)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to decode incoming message")
+	}
+
+	e.handlerLock.Lock()
+	handler, activeSink := e.handler, e.activeSink
+	if handler != nil && (methodCall.Method == "listen" || methodCall.Method == "cancel") {
+		// Both calls end the active stream.
+		e.activeSink = nil
+	}
+	e.handlerLock.Unlock()
+
+	if handler == nil {
+		// An empty reply is interpreted as not implemented by the dart side.
+		return nil, nil
+	}
+
+	switch methodCall.Method {
+	case "listen":
+		if activeSink != nil {
+			// The dart side listens again without cancelling, the previous
+			// stream is cancelled first.
+			activeSink.end()
+			err = handler.OnCancel(nil)
+			if err != nil {
+				log.Printf("failed to cancel the previous stream on channel %s: %v\n", e.channelName, err)
+			}
+		}
+		sink := &EventSink{eventChannel: e}
+		err = handler.OnListen(methodCall.Arguments, sink)
+		if err != nil {
+			return e.methodCodec.EncodeErrorEnvelope("error", err.Error(), nil)
+		}
+		e.handlerLock.Lock()
+		e.activeSink = sink
+		e.handlerLock.Unlock()
+		return e.methodCodec.EncodeSuccessEnvelope(nil)
+
+	case "cancel":
+		if activeSink == nil {
+			return e.methodCodec.EncodeErrorEnvelope("error", "No active stream to cancel", nil)
+		}
+		activeSink.end()
+		err = handler.OnCancel(methodCall.Arguments)
+		if err != nil {
+			return e.methodCodec.EncodeErrorEnvelope("error", err.Error(), nil)
+		}
+		return e.methodCodec.EncodeSuccessEnvelope(nil)
+
+	default:
+		return nil, nil
+	}
+}
+
+// StreamHandler defines the interface for a stream handler setup and
+// tear-down requests.
+type StreamHandler interface {
+	// OnListen handles a request to set up an event stream. The events are
+	// sent with the sink, which can be kept and used from any goroutine
+	// until the stream is cancelled or ended. A returned error is sent to
+	// the dart side and the stream is not set up.
+	OnListen(arguments interface{}, sink *EventSink) error
+	// OnCancel handles a request to tear down the most recently created
+	// event stream. The sink of the stream is ended before OnCancel is
+	// called, events sent on it are dropped.
+	OnCancel(arguments interface{}) error
+}
+
+// EventSink defines the sink used by a StreamHandler to send events to the
+// dart side. It is safe for concurrent use.
+type EventSink struct {
+	eventChannel *EventChannel
+
+	hasEnded bool
+	lock     sync.Mutex
+}
+
+// Success sends a successful event on the stream. The event must be
+// supported by the codec of the channel.
+func (es *EventSink) Success(event interface{}) {
+	es.send(func() ([]byte, error) {
+		return es.eventChannel.methodCodec.EncodeSuccessEnvelope(event)
+	})
+}
+
+// Error sends an error event on the stream, received as a
+// PlatformException by the dart side.
+func (es *EventSink) Error(errorCode string, errorMessage string, errorDetails interface{}) {
+	es.send(func() ([]byte, error) {
+		return es.eventChannel.methodCodec.EncodeErrorEnvelope(errorCode, errorMessage, errorDetails)
+	})
+}
+
+// EndOfStream closes the stream on the dart side, the sink must not be used
+// afterwards.
+func (es *EventSink) EndOfStream() {
+	es.send(func() ([]byte, error) {
+		// An empty message closes the stream on the dart side.
+		return nil, nil
+	})
+	es.end()
+}
+
+func (es *EventSink) send(encode func() ([]byte, error)) {
+	es.lock.Lock()
+	defer es.lock.Unlock()
+	if es.hasEnded {
+		return
+	}
+
+	binaryMessage, err := encode()
+	if err != nil {
+		log.Printf("failed to encode event on channel %s: %v\n", es.eventChannel.channelName, err)
+		return
+	}
+	err = es.eventChannel.messenger.Send(es.eventChannel.channelName, binaryMessage)
+	if err != nil {
+		log.Printf("failed to send event on channel %s: %v\n", es.eventChannel.channelName, err)
+	}
+}
+
+// end marks the sink as ended, subsequent events are dropped.
+func (es *EventSink) end() {
+	es.lock.Lock()
+	es.hasEnded = true
+	es.lock.Unlock()
+}
@@ -0,0 +1,211 @@
+package plugin_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/go-flutter-desktop/go-flutter/plugin"
+	"github.com/go-flutter-desktop/go-flutter/plugin/plugintest"
+	"github.com/pkg/errors"
+)
+
+// testStreamHandler records the calls of the event channel, and keeps the
+// sink of the active stream.
+type testStreamHandler struct {
+	sink      *plugin.EventSink
+	arguments interface{}
+	cancels   int
+	listenErr error
+}
+
+func (h *testStreamHandler) OnListen(arguments interface{}, sink *plugin.EventSink) error {
+	if h.listenErr != nil {
+		return h.listenErr
+	}
+	h.arguments = arguments
+	h.sink = sink
+	return nil
+}
+
+func (h *testStreamHandler) OnCancel(arguments interface{}) error {
+	h.cancels++
+	return nil
+}
+
+// sentEvents decodes the events sent on the channel, nil for the end of the
+// stream.
+func sentEvents(t *testing.T, messenger *plugintest.Messenger) []interface{} {
+	t.Helper()
+	var events []interface{}
+	for _, message := range messenger.Sent() {
+		if len(message.Data) == 0 {
+			events = append(events, nil)
+			continue
+		}
+		event, err := plugin.StandardMethodCodec{}.DecodeEnvelope(message.Data)
+		if err != nil {
+			events = append(events, err)
+			continue
+		}
+		events = append(events, event)
+	}
+	return events
+}
+
+func TestEventChannelListenCancel(t *testing.T) {
+	messenger := plugintest.NewMessenger()
+	codec := plugin.StandardMethodCodec{}
+	handler := &testStreamHandler{}
+	plugin.NewEventChannel(messenger, testChannel, codec).Handle(handler)
+
+	_, err := messenger.InvokeMethod(context.Background(), testChannel, codec, "listen", "arguments")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if handler.sink == nil || handler.arguments != "arguments" {
+		t.Fatalf("OnListen called with %#v, expected \"arguments\"", handler.arguments)
+	}
+	handler.sink.Success("first")
+	handler.sink.Error("CODE", "message", nil)
+
+	_, err = messenger.InvokeMethod(context.Background(), testChannel, codec, "cancel", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if handler.cancels != 1 {
+		t.Fatalf("OnCancel called %d times, expected once", handler.cancels)
+	}
+	// The sink of a cancelled stream drops the events.
+	handler.sink.Success("dropped")
+
+	events := sentEvents(t, messenger)
+	if len(events) != 2 || events[0] != "first" {
+		t.Fatalf("sent %#v, expected \"first\" and an error", events)
+	}
+	if flutterErr, ok := events[1].(*plugin.FlutterError); !ok || flutterErr.Code != "CODE" {
+		t.Fatalf("sent %#v, expected an error with code CODE", events[1])
+	}
+
+	// Cancelling again is an error, there is no active stream.
+	_, err = messenger.InvokeMethod(context.Background(), testChannel, codec, "cancel", nil)
+	if _, ok := err.(*plugin.FlutterError); !ok {
+		t.Fatalf("cancel without stream returned %v, expected a *plugin.FlutterError", err)
+	}
+}
+
+func TestEventChannelEndOfStream(t *testing.T) {
+	messenger := plugintest.NewMessenger()
+	codec := plugin.StandardMethodCodec{}
+	handler := &testStreamHandler{}
+	plugin.NewEventChannel(messenger, testChannel, codec).Handle(handler)
+
+	_, err := messenger.InvokeMethod(context.Background(), testChannel, codec, "listen", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	handler.sink.Success("last")
+	handler.sink.EndOfStream()
+	handler.sink.Success("dropped")
+
+	events := sentEvents(t, messenger)
+	if len(events) != 2 || events[0] != "last" || events[1] != nil {
+		t.Fatalf("sent %#v, expected \"last\" and the end of stream", events)
+	}
+}
+
+func TestEventChannelListenAgain(t *testing.T) {
+	messenger := plugintest.NewMessenger()
+	codec := plugin.StandardMethodCodec{}
+	handler := &testStreamHandler{}
+	plugin.NewEventChannel(messenger, testChannel, codec).Handle(handler)
+
+	_, err := messenger.InvokeMethod(context.Background(), testChannel, codec, "listen", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	previousSink := handler.sink
+	_, err = messenger.InvokeMethod(context.Background(), testChannel, codec, "listen", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if handler.cancels != 1 {
+		t.Fatalf("OnCancel called %d times, expected the previous stream to be cancelled", handler.cancels)
+	}
+	previousSink.Success("dropped")
+	handler.sink.Success("current")
+
+	events := sentEvents(t, messenger)
+	if len(events) != 1 || events[0] != "current" {
+		t.Fatalf("sent %#v, expected only \"current\"", events)
+	}
+}
+
+func TestEventChannelListenError(t *testing.T) {
+	messenger := plugintest.NewMessenger()
+	codec := plugin.StandardMethodCodec{}
+	handler := &testStreamHandler{listenErr: errors.New("unavailable")}
+	plugin.NewEventChannel(messenger, testChannel, codec).Handle(handler)
+
+	_, err := messenger.InvokeMethod(context.Background(), testChannel, codec, "listen", nil)
+	flutterErr, ok := err.(*plugin.FlutterError)
+	if !ok || flutterErr.Message != "unavailable" {
+		t.Fatalf("listen returned %v, expected a *plugin.FlutterError with the OnListen error", err)
+	}
+}
+
+func TestEventChannelWithoutHandler(t *testing.T) {
+	messenger := plugintest.NewMessenger()
+	codec := plugin.StandardMethodCodec{}
+	plugin.NewEventChannel(messenger, testChannel, codec)
+
+	_, err := messenger.InvokeMethod(context.Background(), testChannel, codec, "listen", nil)
+	if err != plugin.ErrMethodNotImplemented {
+		t.Fatalf("listen returned %v, expected ErrMethodNotImplemented", err)
+	}
+}
+
+// replacingStreamHandler replaces the handler of its channel when listened
+// to.
+type replacingStreamHandler struct {
+	channel     *plugin.EventChannel
+	replacement plugin.StreamHandler
+}
+
+func (h *replacingStreamHandler) OnListen(arguments interface{}, sink *plugin.EventSink) error {
+	h.channel.Handle(h.replacement)
+	return nil
+}
+
+func (h *replacingStreamHandler) OnCancel(arguments interface{}) error {
+	return nil
+}
+
+func TestEventChannelHandleFromHandler(t *testing.T) {
+	messenger := plugintest.NewMessenger()
+	codec := plugin.StandardMethodCodec{}
+	channel := plugin.NewEventChannel(messenger, testChannel, codec)
+	replacement := &testStreamHandler{}
+	channel.Handle(&replacingStreamHandler{channel: channel, replacement: replacement})
+
+	done := make(chan error)
+	go func() {
+		_, err := messenger.InvokeMethod(context.Background(), testChannel, codec, "listen", nil)
+		if err == nil {
+			// The replacement handler cancels the stream of the first one.
+			_, err = messenger.InvokeMethod(context.Background(), testChannel, codec, "cancel", nil)
+		}
+		done <- err
+	}()
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatal(err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("deadlock: Handle called by the stream handler never returned")
+	}
+	if replacement.cancels != 1 {
+		t.Fatalf("replacement OnCancel called %d times, expected once", replacement.cancels)
+	}
+}
@@ -0,0 +1,135 @@
+package plugin
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestJSONMessageCodec(t *testing.T) {
+	codec := JSONMessageCodec{}
+	data, err := codec.EncodeMessage(map[string]interface{}{"a": []int{1, 2}})
+	if err != nil {
+		t.Fatalf("encode: %v", err)
+	}
+	value, err := codec.DecodeMessage(data)
+	if err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	raw, ok := value.(json.RawMessage)
+	if !ok {
+		t.Fatalf("decoded %T, expected json.RawMessage", value)
+	}
+	var decoded map[string][]int
+	err = json.Unmarshal(raw, &decoded)
+	if err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+	if !reflect.DeepEqual(decoded, map[string][]int{"a": {1, 2}}) {
+		t.Fatalf("decoded %v", decoded)
+	}
+
+	data, err = codec.EncodeMessage(nil)
+	if err != nil || data != nil {
+		t.Fatalf("encode nil: %v, %v", data, err)
+	}
+	value, err = codec.DecodeMessage(nil)
+	if err != nil || value != nil {
+		t.Fatalf("decode nil: %v, %v", value, err)
+	}
+	_, err = codec.DecodeMessage([]byte(`{"a":`))
+	if err == nil {
+		t.Fatal("expected an error decoding invalid json")
+	}
+	_, err = codec.EncodeMessage(make(chan int))
+	if err == nil {
+		t.Fatal("expected an error encoding a channel")
+	}
+}
+
+func TestJSONMethodCodecMethodCall(t *testing.T) {
+	codec := JSONMethodCodec{}
+	data, err := codec.EncodeMethodCall(MethodCall{
+		Method:    "method",
+		Arguments: map[string]interface{}{"a": "b"},
+	})
+	if err != nil {
+		t.Fatalf("encode: %v", err)
+	}
+	if string(data) != `{"method":"method","args":{"a":"b"}}` {
+		t.Fatalf("encoded %s", data)
+	}
+	methodCall, err := codec.DecodeMethodCall(data)
+	if err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if methodCall.Method != "method" {
+		t.Fatalf("decoded method %q", methodCall.Method)
+	}
+	args, ok := methodCall.Arguments.(json.RawMessage)
+	if !ok || string(args) != `{"a":"b"}` {
+		t.Fatalf("decoded arguments %#v", methodCall.Arguments)
+	}
+
+	_, err = codec.DecodeMethodCall([]byte(`{"method":`))
+	if err == nil {
+		t.Fatal("expected an error decoding a truncated method call")
+	}
+}
+
+func TestJSONMethodCodecEnvelope(t *testing.T) {
+	codec := JSONMethodCodec{}
+
+	data, err := codec.EncodeSuccessEnvelope("result")
+	if err != nil {
+		t.Fatalf("encode success: %v", err)
+	}
+	if string(data) != `["result"]` {
+		t.Fatalf("encoded success %s", data)
+	}
+	result, err := codec.DecodeEnvelope(data)
+	if err != nil {
+		t.Fatalf("decode success: %v", err)
+	}
+	if raw, ok := result.(json.RawMessage); !ok || string(raw) != `"result"` {
+		t.Fatalf("decoded success %#v", result)
+	}
+
+	tests := []struct {
+		flutterError FlutterError
+		encoded      string
+	}{
+		{FlutterError{Code: "code"}, `["code",null,null]`},
+		{FlutterError{Code: "code", Message: "message", Details: 1}, `["code","message",1]`},
+	}
+	for _, test := range tests {
+		data, err = codec.EncodeErrorEnvelope(test.flutterError.Code, test.flutterError.Message, test.flutterError.Details)
+		if err != nil {
+			t.Fatalf("encode error: %v", err)
+		}
+		if string(data) != test.encoded {
+			t.Fatalf("encoded error %s, expected %s", data, test.encoded)
+		}
+		result, err = codec.DecodeEnvelope(data)
+		if result != nil {
+			t.Fatalf("decoded result %#v, expected nil", result)
+		}
+		flutterError, ok := err.(*FlutterError)
+		if !ok {
+			t.Fatalf("decoded error %#v, expected a *FlutterError", err)
+		}
+		if flutterError.Code != test.flutterError.Code || flutterError.Message != test.flutterError.Message {
+			t.Fatalf("decoded %#v, expected %#v", flutterError, test.flutterError)
+		}
+	}
+
+	for _, malformed := range []string{``, `[`, `[]`, `["a","b"]`, `[1,null,null]`, `["code",1,null]`} {
+		_, err = codec.DecodeEnvelope([]byte(malformed))
+		if err == nil {
+			t.Fatalf("expected an error decoding %q", malformed)
+		}
+		if _, ok := err.(*FlutterError); ok {
+			t.Fatalf("malformed envelope %q decoded as a FlutterError: %v", malformed, err)
+		}
+	}
+}
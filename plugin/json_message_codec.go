@@ -0,0 +1,40 @@
+package plugin
+
+import (
+	"encoding/json"
+
+	"github.com/pkg/errors"
+)
+
+// JSONMessageCodec implements a MessageCodec using UTF-8 encoded JSON
+// messages.
+//
+// Decoded messages are returned as json.RawMessage so they can be
+// unmarshalled into the type expected by the caller.
+type JSONMessageCodec struct{}
+
+var _ MessageCodec = JSONMessageCodec{} // compile-time type check
+
+// EncodeMessage encodes message to JSON, message must be supported by
+// encoding/json.
+func (JSONMessageCodec) EncodeMessage(message interface{}) ([]byte, error) {
+	if message == nil {
+		return nil, nil
+	}
+	binaryMessage, err := json.Marshal(message)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to encode json message")
+	}
+	return binaryMessage, nil
+}
+
+// DecodeMessage returns the JSON message as a json.RawMessage.
+func (JSONMessageCodec) DecodeMessage(binaryMessage []byte) (interface{}, error) {
+	if binaryMessage == nil {
+		return nil, nil
+	}
+	if !json.Valid(binaryMessage) {
+		return nil, errors.New("failed to decode json message: invalid json")
+	}
+	return json.RawMessage(binaryMessage), nil
+}
@@ -0,0 +1,97 @@
+package plugin
+
+import (
+	"encoding/json"
+
+	"github.com/pkg/errors"
+)
+
+// JSONMethodCodec implements a MethodCodec using JSON for message encoding.
+//
+// Decoded arguments and results are returned as json.RawMessage so they can
+// be unmarshalled into the type expected by the caller. Values given to the
+// encoding methods must be supported by encoding/json.
+type JSONMethodCodec struct{}
+
+var _ MethodCodec = JSONMethodCodec{} // compile-time type check
+
+// jsonMethodCall is the JSON representation of a MethodCall.
+type jsonMethodCall struct {
+	Method string          `json:"method"`
+	Args   json.RawMessage `json:"args"`
+}
+
+// EncodeMethodCall encodes the MethodCall into binary.
+func (j JSONMethodCodec) EncodeMethodCall(methodCall MethodCall) ([]byte, error) {
+	args, err := json.Marshal(methodCall.Arguments)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to encode method arguments")
+	}
+	return json.Marshal(&jsonMethodCall{
+		Method: methodCall.Method,
+		Args:   args,
+	})
+}
+
+// DecodeMethodCall decodes the MethodCall from binary. The arguments of the
+// returned MethodCall are a json.RawMessage.
+func (j JSONMethodCodec) DecodeMethodCall(data []byte) (MethodCall, error) {
+	var call jsonMethodCall
+	err := json.Unmarshal(data, &call)
+	if err != nil {
+		return MethodCall{}, errors.Wrap(err, "failed to decode json method call")
+	}
+	return MethodCall{
+		Method:    call.Method,
+		Arguments: call.Args,
+	}, nil
+}
+
+// EncodeSuccessEnvelope encodes a successful result into a binary envelope.
+func (j JSONMethodCodec) EncodeSuccessEnvelope(result interface{}) ([]byte, error) {
+	return json.Marshal([]interface{}{result})
+}
+
+// EncodeErrorEnvelope encodes an error result into a binary envelope.
+// message is encoded as null when empty.
+func (j JSONMethodCodec) EncodeErrorEnvelope(code string, message string, details interface{}) ([]byte, error) {
+	var jsonMessage interface{}
+	if message != "" {
+		jsonMessage = message
+	}
+	return json.Marshal([]interface{}{code, jsonMessage, details})
+}
+
+// DecodeEnvelope decodes a result envelope from binary. The result of a
+// success envelope is a json.RawMessage, an error envelope is returned as a
+// *FlutterError with the details as a json.RawMessage.
+func (j JSONMethodCodec) DecodeEnvelope(envelope []byte) (interface{}, error) {
+	var fields []json.RawMessage
+	err := json.Unmarshal(envelope, &fields)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to decode json envelope")
+	}
+	switch len(fields) {
+	case 1:
+		return fields[0], nil
+	case 3:
+		flutterError := &FlutterError{
+			Details: fields[2],
+		}
+		err = json.Unmarshal(fields[0], &flutterError.Code)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to decode error code")
+		}
+		var message *string
+		err = json.Unmarshal(fields[1], &message)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to decode error message")
+		}
+		if message != nil {
+			flutterError.Message = *message
+		}
+		return nil, flutterError
+	default:
+		return nil, errors.Errorf("invalid json envelope: expected 1 or 3 elements, got %d", len(fields))
+	}
+}
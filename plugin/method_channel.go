@@ -0,0 +1,153 @@
+package plugin
+
+import (
+	"context"
+	"sync"
+
+	"github.com/pkg/errors"
+)
+
+// ErrMethodNotImplemented is returned by InvokeMethodWithReply when the
+// flutter application has no handler for the invoked method.
+var ErrMethodNotImplemented = errors.New("method not implemented")
+
+// MethodChannel provides a way for flutter applications and hosts to
+// communicate using asynchronous method calls.
+// It must be used with a codec, for example the StandardMethodCodec. For
+// more information please read
+// https://flutter.dev/docs/development/platform-integration/platform-channels
+type MethodChannel struct {
+	messenger   BinaryMessenger
+	channelName string
+	methodCodec MethodCodec
+
+	methods     map[string]AsyncMethodHandler
+	methodsLock sync.RWMutex
+}
+
+// NewMethodChannel creates a new method channel and registers it on the
+// messenger.
+func NewMethodChannel(messenger BinaryMessenger, channelName string, methodCodec MethodCodec) *MethodChannel {
+	mc := &MethodChannel{
+		messenger:   messenger,
+		channelName: channelName,
+		methodCodec: methodCodec,
+		methods:     make(map[string]AsyncMethodHandler),
+	}
+	messenger.SetChannelHandler(channelName, mc.handleChannelMessage)
+	return mc
+}
+
+// InvokeMethod sends a method call to the flutter application. The
+// arguments must be supported by the codec of the channel.
+func (m *MethodChannel) InvokeMethod(name string, arguments interface{}) error {
+	encodedMessage, err := m.methodCodec.EncodeMethodCall(MethodCall{
+		Method:    name,
+		Arguments: arguments,
+	})
+	if err != nil {
+		return errors.Wrap(err, "failed to encode methodCall")
+	}
+	err = m.messenger.Send(m.channelName, encodedMessage)
+	if err != nil {
+		return errors.Wrap(err, "failed to send methodCall")
+	}
+	return nil
+}
+
+// InvokeMethodWithReply sends a method call to the flutter application and
+// waits for the result. The arguments must be supported by the codec of the
+// channel, the reply is decoded with the same codec.
+//
+// An error envelope sent by the flutter application is returned as a
+// *FlutterError. ErrMethodNotImplemented is returned when the flutter
+// application has no handler for the method.
+// See BinaryMessenger.SendWithReply for the handling of the context.
+func (m *MethodChannel) InvokeMethodWithReply(ctx context.Context, name string, arguments interface{}) (reply interface{}, err error) {
+	encodedMessage, err := m.methodCodec.EncodeMethodCall(MethodCall{
+		Method:    name,
+		Arguments: arguments,
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to encode methodCall")
+	}
+	encodedReply, err := m.messenger.SendWithReply(ctx, m.channelName, encodedMessage)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to send methodCall")
+	}
+	if len(encodedReply) == 0 {
+		// An empty reply means the method is not implemented on the dart side.
+		return nil, ErrMethodNotImplemented
+	}
+	return m.methodCodec.DecodeEnvelope(encodedReply)
+}
+
+// Handle registers a method handler for method calls with given name.
+// Registering a handler for a name that already has one replaces it, a nil
+// handler removes it. Method calls without a handler are answered as not
+// implemented, which results in a MissingPluginException on the dart side.
+func (m *MethodChannel) Handle(methodName string, handler MethodHandler) {
+	if handler == nil {
+		m.HandleAsync(methodName, nil)
+		return
+	}
+	m.HandleAsync(methodName, syncMethodHandler{handler: handler})
+}
+
+// HandleFunc is a shorthand for m.Handle(methodName, MethodHandlerFunc(f))
+func (m *MethodChannel) HandleFunc(methodName string, f func(arguments interface{}) (reply interface{}, err error)) {
+	if f == nil {
+		m.Handle(methodName, nil)
+		return
+	}
+	m.Handle(methodName, MethodHandlerFunc(f))
+}
+
+// HandleAsync registers an asynchronous method handler for method calls with
+// given name. The handler sends the result with the MethodReply, possibly
+// after it has returned. See Handle for the registration rules.
+func (m *MethodChannel) HandleAsync(methodName string, handler AsyncMethodHandler) {
+	m.methodsLock.Lock()
+	if handler == nil {
+		delete(m.methods, methodName)
+	} else {
+		m.methods[methodName] = handler
+	}
+	m.methodsLock.Unlock()
+}
+
+// HandleFuncAsync is a shorthand for
+// m.HandleAsync(methodName, AsyncMethodHandlerFunc(f))
+func (m *MethodChannel) HandleFuncAsync(methodName string, f func(arguments interface{}, reply *MethodReply)) {
+	if f == nil {
+		m.HandleAsync(methodName, nil)
+		return
+	}
+	m.HandleAsync(methodName, AsyncMethodHandlerFunc(f))
+}
+
+// handleChannelMessage decodes incoming binary message to a method call and
+// calls the handler with a MethodReply wrapping the responseSender.
+func (m *MethodChannel) handleChannelMessage(binaryMessage []byte, responseSender ResponseSender) error {
+	methodCall, err := m.methodCodec.DecodeMethodCall(binaryMessage)
+	if err != nil {
+		return errors.Wrap(err, "failed to decode incoming message")
+	}
+
+	reply := &MethodReply{
+		channelName:    m.channelName,
+		methodCodec:    m.methodCodec,
+		responseSender: responseSender,
+	}
+
+	m.methodsLock.RLock()
+	handler, ok := m.methods[methodCall.Method]
+	m.methodsLock.RUnlock()
+	if !ok {
+		reply.NotImplemented()
+		return nil
+	}
+
+	handler.HandleMethodAsync(methodCall.Arguments, reply)
+	return nil
+}
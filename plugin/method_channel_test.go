@@ -0,0 +1,178 @@
+package plugin_test
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/go-flutter-desktop/go-flutter/plugin"
+	"github.com/go-flutter-desktop/go-flutter/plugin/plugintest"
+	"github.com/pkg/errors"
+)
+
+const testChannel = "test/channel"
+
+func TestMethodChannelDispatch(t *testing.T) {
+	messenger := plugintest.NewMessenger()
+	channel := plugin.NewMethodChannel(messenger, testChannel, plugin.StandardMethodCodec{})
+	channel.HandleFunc("echo", func(arguments interface{}) (interface{}, error) {
+		return arguments, nil
+	})
+	channel.HandleFuncAsync("later", func(arguments interface{}, reply *plugin.MethodReply) {
+		go reply.Success("done")
+	})
+
+	tests := []struct {
+		method    string
+		arguments interface{}
+		result    interface{}
+	}{
+		{"echo", "hello", "hello"},
+		{"echo", []interface{}{"a", true}, []interface{}{"a", true}},
+		{"later", nil, "done"},
+	}
+	for _, test := range tests {
+		result, err := messenger.InvokeMethod(context.Background(), testChannel, plugin.StandardMethodCodec{}, test.method, test.arguments)
+		if err != nil {
+			t.Fatalf("%s: %v", test.method, err)
+		}
+		if !reflect.DeepEqual(result, test.result) {
+			t.Fatalf("%s returned %#v, expected %#v", test.method, result, test.result)
+		}
+	}
+}
+
+func TestMethodChannelNotImplemented(t *testing.T) {
+	messenger := plugintest.NewMessenger()
+	channel := plugin.NewMethodChannel(messenger, testChannel, plugin.StandardMethodCodec{})
+	channel.HandleFunc("removed", func(arguments interface{}) (interface{}, error) {
+		return nil, nil
+	})
+	channel.HandleFunc("removed", nil)
+
+	for _, method := range []string{"unknown", "removed"} {
+		_, err := messenger.InvokeMethod(context.Background(), testChannel, plugin.StandardMethodCodec{}, method, nil)
+		if err != plugin.ErrMethodNotImplemented {
+			t.Fatalf("%s returned %v, expected ErrMethodNotImplemented", method, err)
+		}
+	}
+}
+
+func TestMethodChannelErrorEnvelope(t *testing.T) {
+	messenger := plugintest.NewMessenger()
+	channel := plugin.NewMethodChannel(messenger, testChannel, plugin.StandardMethodCodec{})
+	channel.HandleFunc("flutterError", func(arguments interface{}) (interface{}, error) {
+		return nil, errors.Wrap(&plugin.FlutterError{Code: "BAD_ARGS", Message: "bad arguments", Details: "details"}, "wrapped")
+	})
+	channel.HandleFunc("goError", func(arguments interface{}) (interface{}, error) {
+		return nil, errors.New("failure")
+	})
+	channel.HandleFuncAsync("asyncError", func(arguments interface{}, reply *plugin.MethodReply) {
+		reply.Error("ASYNC", "async failure", nil)
+	})
+
+	tests := []struct {
+		method   string
+		expected plugin.FlutterError
+	}{
+		{"flutterError", plugin.FlutterError{Code: "BAD_ARGS", Message: "bad arguments", Details: "details"}},
+		{"goError", plugin.FlutterError{Code: "error", Message: "failure"}},
+		{"asyncError", plugin.FlutterError{Code: "ASYNC", Message: "async failure"}},
+	}
+	for _, test := range tests {
+		_, err := messenger.InvokeMethod(context.Background(), testChannel, plugin.StandardMethodCodec{}, test.method, nil)
+		flutterErr, ok := err.(*plugin.FlutterError)
+		if !ok {
+			t.Fatalf("%s returned %v, expected a *plugin.FlutterError", test.method, err)
+		}
+		if !reflect.DeepEqual(*flutterErr, test.expected) {
+			t.Fatalf("%s returned %#v, expected %#v", test.method, *flutterErr, test.expected)
+		}
+	}
+}
+
+func TestMethodChannelInvokeMethod(t *testing.T) {
+	messenger := plugintest.NewMessenger()
+	codec := plugin.StandardMethodCodec{}
+	channel := plugin.NewMethodChannel(messenger, testChannel, codec)
+
+	err := channel.InvokeMethod("notify", "event")
+	if err != nil {
+		t.Fatal(err)
+	}
+	sent := messenger.Sent()
+	if len(sent) != 1 || sent[0].Channel != testChannel {
+		t.Fatalf("sent %v, expected one message on %s", sent, testChannel)
+	}
+	methodCall, err := codec.DecodeMethodCall(sent[0].Data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if methodCall.Method != "notify" || methodCall.Arguments != "event" {
+		t.Fatalf("sent %#v, expected notify(event)", methodCall)
+	}
+}
+
+func TestMethodChannelInvokeMethodWithReply(t *testing.T) {
+	messenger := plugintest.NewMessenger()
+	codec := plugin.StandardMethodCodec{}
+	channel := plugin.NewMethodChannel(messenger, testChannel, codec)
+
+	// The dart side of the test answers by method name.
+	messenger.ReplyWith(testChannel, func(message []byte) []byte {
+		methodCall, err := codec.DecodeMethodCall(message)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var reply []byte
+		switch methodCall.Method {
+		case "success":
+			reply, err = codec.EncodeSuccessEnvelope("result")
+		case "error":
+			reply, err = codec.EncodeErrorEnvelope("DART", "dart failure", nil)
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		return reply
+	})
+
+	result, err := channel.InvokeMethodWithReply(context.Background(), "success", nil)
+	if err != nil || result != "result" {
+		t.Fatalf("success returned %#v, %v, expected \"result\"", result, err)
+	}
+	_, err = channel.InvokeMethodWithReply(context.Background(), "error", nil)
+	if flutterErr, ok := err.(*plugin.FlutterError); !ok || flutterErr.Code != "DART" {
+		t.Fatalf("error returned %v, expected a *plugin.FlutterError with code DART", err)
+	}
+	_, err = channel.InvokeMethodWithReply(context.Background(), "unknown", nil)
+	if err != plugin.ErrMethodNotImplemented {
+		t.Fatalf("unknown returned %v, expected ErrMethodNotImplemented", err)
+	}
+}
+
+func TestMethodChannelErrorEncodingFailure(t *testing.T) {
+	messenger := plugintest.NewMessenger()
+	channel := plugin.NewMethodChannel(messenger, testChannel, plugin.StandardMethodCodec{})
+	channel.HandleFuncAsync("unsupportedDetails", func(arguments interface{}, reply *plugin.MethodReply) {
+		reply.Error("CODE", "message", struct{}{})
+	})
+	channel.HandleFuncAsync("unsupportedResult", func(arguments interface{}, reply *plugin.MethodReply) {
+		reply.Success(struct{}{})
+	})
+
+	tests := []struct {
+		method  string
+		message string
+	}{
+		{"unsupportedDetails", "failed to encode error"},
+		{"unsupportedResult", "failed to encode result"},
+	}
+	for _, test := range tests {
+		_, err := messenger.InvokeMethod(context.Background(), testChannel, plugin.StandardMethodCodec{}, test.method, nil)
+		flutterErr, ok := err.(*plugin.FlutterError)
+		if !ok || flutterErr.Code != "error" || flutterErr.Message != test.message {
+			t.Fatalf("%s returned %v, expected a *plugin.FlutterError with message %q", test.method, err, test.message)
+		}
+	}
+}
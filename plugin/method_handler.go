@@ -0,0 +1,59 @@
+package plugin
+
+// MethodHandler defines the interface for a method handler.
+type MethodHandler interface {
+	// HandleMethod is called whenever an incoming method call is received on
+	// the channel the handler is registered with.
+	//
+	// The returned reply is encoded in a success envelope, its type must be
+	// supported by the codec of the channel. A returned error is encoded in
+	// an error envelope, a *FlutterError is sent with its own code, message
+	// and details.
+	HandleMethod(arguments interface{}) (reply interface{}, err error)
+}
+
+// The MethodHandlerFunc type is an adapter to allow the use of ordinary
+// functions as method handlers. If f is a function with the appropriate
+// signature, MethodHandlerFunc(f) is a MethodHandler that calls f.
+type MethodHandlerFunc func(arguments interface{}) (reply interface{}, err error)
+
+// HandleMethod calls f(arguments).
+func (f MethodHandlerFunc) HandleMethod(arguments interface{}) (reply interface{}, err error) {
+	return f(arguments)
+}
+
+// AsyncMethodHandler defines the interface for a method handler that sends
+// its result later, e.g.: once a goroutine has completed the work.
+type AsyncMethodHandler interface {
+	// HandleMethodAsync is called whenever an incoming method call is
+	// received on the channel the handler is registered with. The result
+	// must be sent exactly once with the reply, which can be used from any
+	// goroutine.
+	HandleMethodAsync(arguments interface{}, reply *MethodReply)
+}
+
+// The AsyncMethodHandlerFunc type is an adapter to allow the use of ordinary
+// functions as asynchronous method handlers. If f is a function with the
+// appropriate signature, AsyncMethodHandlerFunc(f) is an AsyncMethodHandler
+// that calls f.
+type AsyncMethodHandlerFunc func(arguments interface{}, reply *MethodReply)
+
+// HandleMethodAsync calls f(arguments, reply).
+func (f AsyncMethodHandlerFunc) HandleMethodAsync(arguments interface{}, reply *MethodReply) {
+	f(arguments, reply)
+}
+
+// syncMethodHandler adapts a MethodHandler to an AsyncMethodHandler, the
+// result is sent as soon as HandleMethod returns.
+type syncMethodHandler struct {
+	handler MethodHandler
+}
+
+func (s syncMethodHandler) HandleMethodAsync(arguments interface{}, reply *MethodReply) {
+	result, err := s.handler.HandleMethod(arguments)
+	if err != nil {
+		reply.sendError(err)
+		return
+	}
+	reply.Success(result)
+}
@@ -0,0 +1,66 @@
+package plugin
+
+import (
+	"log"
+
+	"github.com/pkg/errors"
+)
+
+// MethodReply sends the result of a method call to the flutter application.
+// The result is encoded with the codec of the channel the method call was
+// received on.
+//
+// Exactly one of Success, Error or NotImplemented must be called, from any
+// goroutine. Results after the first one are dropped.
+type MethodReply struct {
+	channelName    string
+	methodCodec    MethodCodec
+	responseSender ResponseSender
+}
+
+// Success sends a successful result. The result must be supported by the
+// codec of the channel.
+func (r *MethodReply) Success(result interface{}) {
+	binaryReply, err := r.methodCodec.EncodeSuccessEnvelope(result)
+	if err != nil {
+		log.Printf("failed to encode result on channel %s: %v\n", r.channelName, err)
+		r.Error("error", "failed to encode result", nil)
+		return
+	}
+	r.responseSender.Send(binaryReply)
+}
+
+// Error sends an error result, received as a PlatformException by the dart
+// side.
+func (r *MethodReply) Error(code string, message string, details interface{}) {
+	binaryReply, err := r.methodCodec.EncodeErrorEnvelope(code, message, details)
+	if err != nil {
+		log.Printf("failed to encode error on channel %s: %v\n", r.channelName, err)
+		// the dart side still receives a PlatformException, without the
+		// details that can't be encoded
+		binaryReply, err = r.methodCodec.EncodeErrorEnvelope("error", "failed to encode error", nil)
+		if err != nil {
+			log.Printf("failed to encode error on channel %s: %v\n", r.channelName, err)
+			binaryReply = nil
+		}
+	}
+	r.responseSender.Send(binaryReply)
+}
+
+// NotImplemented replies that the method is not implemented, received as a
+// MissingPluginException by the dart side.
+func (r *MethodReply) NotImplemented() {
+	// An empty reply is interpreted as not implemented by the dart side.
+	r.responseSender.Send(nil)
+}
+
+// sendError sends the error returned by a MethodHandler. A *FlutterError
+// keeps its code, message and details, other errors are sent with the
+// "error" code.
+func (r *MethodReply) sendError(handlerErr error) {
+	if flutterErr, ok := errors.Cause(handlerErr).(*FlutterError); ok {
+		r.Error(flutterErr.Code, flutterErr.Message, flutterErr.Details)
+		return
+	}
+	r.Error("error", handlerErr.Error(), nil)
+}
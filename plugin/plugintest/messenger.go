@@ -0,0 +1,167 @@
+// Package plugintest provides in-memory fakes of the messenger and of the
+// window given to plugins, to unit test plugins with `go test`, without the
+// flutter engine.
+//
+//	messenger := plugintest.NewMessenger()
+//	err := myPlugin.InitPlugin(messenger)
+//	...
+//	result, err := messenger.InvokeMethod(ctx, "my_channel", plugin.StandardMethodCodec{}, "getNumber", nil)
+package plugintest
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/go-flutter-desktop/go-flutter/plugin"
+	"github.com/pkg/errors"
+)
+
+// SentMessage is a message sent by a plugin to the flutter application.
+type SentMessage struct {
+	Channel string
+	Data    []byte
+}
+
+// Messenger is an in-memory plugin.BinaryMessenger. It records the messages
+// sent by the plugins and injects the messages of the flutter application.
+// It is safe for concurrent use.
+type Messenger struct {
+	lock     sync.Mutex
+	channels map[string]plugin.ChannelHandlerFunc
+	replies  map[string]func(message []byte) (reply []byte)
+	sent     []SentMessage
+}
+
+var _ plugin.BinaryMessenger = &Messenger{} // compile-time type check
+
+// NewMessenger creates an empty Messenger.
+func NewMessenger() *Messenger {
+	return &Messenger{
+		channels: make(map[string]plugin.ChannelHandlerFunc),
+		replies:  make(map[string]func(message []byte) (reply []byte)),
+	}
+}
+
+// Send satisfies plugin.BinaryMessenger, the message is recorded.
+func (m *Messenger) Send(channel string, binaryMessage []byte) error {
+	m.lock.Lock()
+	m.sent = append(m.sent, SentMessage{Channel: channel, Data: binaryMessage})
+	m.lock.Unlock()
+	return nil
+}
+
+// SendWithReply satisfies plugin.BinaryMessenger, the message is recorded
+// and answered by the function given to ReplyWith for the channel, with an
+// empty reply otherwise.
+func (m *Messenger) SendWithReply(ctx context.Context, channel string, binaryMessage []byte) ([]byte, error) {
+	m.lock.Lock()
+	m.sent = append(m.sent, SentMessage{Channel: channel, Data: binaryMessage})
+	reply := m.replies[channel]
+	m.lock.Unlock()
+	if reply == nil {
+		return nil, nil
+	}
+	return reply(binaryMessage), nil
+}
+
+// SetChannelHandler satisfies plugin.BinaryMessenger
+func (m *Messenger) SetChannelHandler(channel string, handler plugin.ChannelHandlerFunc) {
+	m.lock.Lock()
+	if handler == nil {
+		delete(m.channels, channel)
+	} else {
+		m.channels[channel] = handler
+	}
+	m.lock.Unlock()
+}
+
+// ReplyWith sets the function answering the messages sent with
+// SendWithReply on channel, acting as the dart side. A nil reply removes it.
+func (m *Messenger) ReplyWith(channel string, reply func(message []byte) (reply []byte)) {
+	m.lock.Lock()
+	if reply == nil {
+		delete(m.replies, channel)
+	} else {
+		m.replies[channel] = reply
+	}
+	m.lock.Unlock()
+}
+
+// Sent returns the messages sent by the plugins so far, in order.
+func (m *Messenger) Sent() []SentMessage {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	return append([]SentMessage(nil), m.sent...)
+}
+
+// Receive injects a message from the flutter application on channel, and
+// waits for the reply of the channel handler, or for the context to be done.
+// An error is returned when no handler is registered for the channel.
+func (m *Messenger) Receive(ctx context.Context, channel string, binaryMessage []byte) (binaryReply []byte, err error) {
+	m.lock.Lock()
+	handler, ok := m.channels[channel]
+	m.lock.Unlock()
+	if !ok {
+		return nil, errors.Errorf("no handler registered for channel %s", channel)
+	}
+
+	responseSender := &responseSender{
+		channel: channel,
+		reply:   make(chan []byte, 1),
+	}
+	err = handler(binaryMessage, responseSender)
+	if err != nil {
+		return nil, errors.Wrapf(err, "handling message on channel %s", channel)
+	}
+	select {
+	case binaryReply = <-responseSender.reply:
+		return binaryReply, nil
+	case <-ctx.Done():
+		return nil, errors.Wrapf(ctx.Err(), "waiting for the reply on channel %s", channel)
+	}
+}
+
+// InvokeMethod injects a method call from the flutter application on
+// channel, and decodes the reply. See Receive for the handling of the
+// context. Like on the dart side, an error envelope is returned as a
+// *plugin.FlutterError and an empty reply as plugin.ErrMethodNotImplemented.
+func (m *Messenger) InvokeMethod(ctx context.Context, channel string, codec plugin.MethodCodec, method string, arguments interface{}) (result interface{}, err error) {
+	binaryMessage, err := codec.EncodeMethodCall(plugin.MethodCall{
+		Method:    method,
+		Arguments: arguments,
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to encode method call")
+	}
+	binaryReply, err := m.Receive(ctx, channel, binaryMessage)
+	if err != nil {
+		return nil, err
+	}
+	if len(binaryReply) == 0 {
+		return nil, plugin.ErrMethodNotImplemented
+	}
+	return codec.DecodeEnvelope(binaryReply)
+}
+
+// responseSender is the plugin.ResponseSender of the messages injected with
+// Receive.
+type responseSender struct {
+	channel string
+	reply   chan []byte
+
+	sent bool
+	lock sync.Mutex
+}
+
+// Send satisfies plugin.ResponseSender. Replying twice is a bug of the
+// plugin under test, it panics.
+func (r *responseSender) Send(binaryReply []byte) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	if r.sent {
+		panic(fmt.Sprintf("plugintest: reply sent twice to the message on channel %s", r.channel))
+	}
+	r.sent = true
+	r.reply <- binaryReply
+}
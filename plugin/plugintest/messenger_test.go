@@ -0,0 +1,135 @@
+package plugintest
+
+import (
+	"bytes"
+	"context"
+	"testing"
+	"time"
+
+	"github.com/go-flutter-desktop/go-flutter/plugin"
+)
+
+func TestMessengerSent(t *testing.T) {
+	messenger := NewMessenger()
+	err := messenger.Send("channel/a", []byte("first"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	reply, err := messenger.SendWithReply(context.Background(), "channel/b", []byte("second"))
+	if err != nil || reply != nil {
+		t.Fatalf("reply without ReplyWith is %q, %v, expected an empty reply", reply, err)
+	}
+
+	messenger.ReplyWith("channel/b", func(message []byte) []byte {
+		return append([]byte("reply to "), message...)
+	})
+	reply, err = messenger.SendWithReply(context.Background(), "channel/b", []byte("third"))
+	if err != nil || string(reply) != "reply to third" {
+		t.Fatalf("reply is %q, %v, expected \"reply to third\"", reply, err)
+	}
+
+	expected := []SentMessage{
+		{"channel/a", []byte("first")},
+		{"channel/b", []byte("second")},
+		{"channel/b", []byte("third")},
+	}
+	sent := messenger.Sent()
+	if len(sent) != len(expected) {
+		t.Fatalf("sent %d messages, expected %d", len(sent), len(expected))
+	}
+	for i := range expected {
+		if sent[i].Channel != expected[i].Channel || !bytes.Equal(sent[i].Data, expected[i].Data) {
+			t.Fatalf("message %d is %s %q, expected %s %q", i, sent[i].Channel, sent[i].Data, expected[i].Channel, expected[i].Data)
+		}
+	}
+}
+
+func TestMessengerReceive(t *testing.T) {
+	messenger := NewMessenger()
+	_, err := messenger.Receive(context.Background(), "channel", []byte("message"))
+	if err == nil {
+		t.Fatal("message received without handler")
+	}
+
+	messenger.SetChannelHandler("channel", func(binaryMessage []byte, responseSender plugin.ResponseSender) error {
+		go responseSender.Send(append([]byte("reply to "), binaryMessage...))
+		return nil
+	})
+	reply, err := messenger.Receive(context.Background(), "channel", []byte("message"))
+	if err != nil || string(reply) != "reply to message" {
+		t.Fatalf("reply is %q, %v, expected \"reply to message\"", reply, err)
+	}
+
+	// A handler that never replies times out with the context.
+	messenger.SetChannelHandler("channel", func(binaryMessage []byte, responseSender plugin.ResponseSender) error {
+		return nil
+	})
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	_, err = messenger.Receive(ctx, "channel", []byte("message"))
+	if err == nil {
+		t.Fatal("reply received from a handler that never replies")
+	}
+
+	messenger.SetChannelHandler("channel", nil)
+	_, err = messenger.Receive(context.Background(), "channel", []byte("message"))
+	if err == nil {
+		t.Fatal("message received by a removed handler")
+	}
+}
+
+func TestMessengerReplyTwice(t *testing.T) {
+	messenger := NewMessenger()
+	var panicked bool
+	messenger.SetChannelHandler("channel", func(binaryMessage []byte, responseSender plugin.ResponseSender) error {
+		responseSender.Send(nil)
+		defer func() {
+			panicked = recover() != nil
+		}()
+		responseSender.Send(nil)
+		return nil
+	})
+	_, err := messenger.Receive(context.Background(), "channel", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !panicked {
+		t.Fatal("second reply didn't panic")
+	}
+}
+
+func TestMessengerInvokeMethod(t *testing.T) {
+	codec := plugin.StandardMethodCodec{}
+	messenger := NewMessenger()
+	messenger.SetChannelHandler("channel", func(binaryMessage []byte, responseSender plugin.ResponseSender) error {
+		methodCall, err := codec.DecodeMethodCall(binaryMessage)
+		if err != nil {
+			return err
+		}
+		var reply []byte
+		switch methodCall.Method {
+		case "success":
+			reply, err = codec.EncodeSuccessEnvelope(methodCall.Arguments)
+		case "error":
+			reply, err = codec.EncodeErrorEnvelope("CODE", "message", nil)
+		}
+		if err != nil {
+			return err
+		}
+		responseSender.Send(reply)
+		return nil
+	})
+
+	result, err := messenger.InvokeMethod(context.Background(), "channel", codec, "success", "arguments")
+	if err != nil || result != "arguments" {
+		t.Fatalf("success returned %#v, %v, expected \"arguments\"", result, err)
+	}
+	_, err = messenger.InvokeMethod(context.Background(), "channel", codec, "error", nil)
+	if flutterErr, ok := err.(*plugin.FlutterError); !ok || flutterErr.Code != "CODE" {
+		t.Fatalf("error returned %v, expected a *plugin.FlutterError with code CODE", err)
+	}
+	_, err = messenger.InvokeMethod(context.Background(), "channel", codec, "unknown", nil)
+	if err != plugin.ErrMethodNotImplemented {
+		t.Fatalf("unknown returned %v, expected ErrMethodNotImplemented", err)
+	}
+}
@@ -0,0 +1,65 @@
+package plugintest
+
+import (
+	"math"
+	"sync"
+
+	"github.com/go-flutter-desktop/go-flutter/plugin"
+)
+
+// Window is an in-memory plugin.Window. Its fields are set and inspected by
+// the test, not while a plugin goroutine uses the window.
+type Window struct {
+	lock sync.Mutex
+
+	Title     string
+	Clipboard string
+	// Width and Height are the size of the window in screen coordinates.
+	Width  int
+	Height int
+	// PixelRatio is the number of framebuffer pixels per screen
+	// coordinate, 1.0 when zero. The framebuffer size is rounded to the
+	// nearest pixel.
+	PixelRatio float64
+}
+
+var _ plugin.Window = &Window{} // compile-time type check
+
+// SetTitle satisfies plugin.Window
+func (w *Window) SetTitle(title string) {
+	w.lock.Lock()
+	w.Title = title
+	w.lock.Unlock()
+}
+
+// GetClipboardString satisfies plugin.Window
+func (w *Window) GetClipboardString() (string, error) {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+	return w.Clipboard, nil
+}
+
+// SetClipboardString satisfies plugin.Window
+func (w *Window) SetClipboardString(str string) {
+	w.lock.Lock()
+	w.Clipboard = str
+	w.lock.Unlock()
+}
+
+// GetSize satisfies plugin.Window
+func (w *Window) GetSize() (width int, height int) {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+	return w.Width, w.Height
+}
+
+// GetFramebufferSize satisfies plugin.Window
+func (w *Window) GetFramebufferSize() (width int, height int) {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+	pixelRatio := w.PixelRatio
+	if pixelRatio == 0 {
+		pixelRatio = 1
+	}
+	return int(math.Round(float64(w.Width) * pixelRatio)), int(math.Round(float64(w.Height) * pixelRatio))
+}
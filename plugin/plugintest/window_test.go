@@ -0,0 +1,39 @@
+package plugintest
+
+import "testing"
+
+func TestWindowFramebufferSize(t *testing.T) {
+	tests := []struct {
+		pixelRatio    float64
+		width, height int
+	}{
+		{0, 800, 600},
+		{1, 800, 600},
+		{2, 1600, 1200},
+		{1.5, 1200, 900},
+		{1.25, 1000, 750},
+	}
+	for _, test := range tests {
+		window := &Window{Width: 800, Height: 600, PixelRatio: test.pixelRatio}
+		width, height := window.GetFramebufferSize()
+		if width != test.width || height != test.height {
+			t.Fatalf("framebuffer size with pixel ratio %v is %dx%d, expected %dx%d", test.pixelRatio, width, height, test.width, test.height)
+		}
+	}
+}
+
+func TestWindowClipboard(t *testing.T) {
+	window := &Window{Clipboard: "initial"}
+	clipboard, err := window.GetClipboardString()
+	if err != nil || clipboard != "initial" {
+		t.Fatalf("clipboard is %q, %v, expected \"initial\"", clipboard, err)
+	}
+	window.SetClipboardString("copied")
+	if window.Clipboard != "copied" {
+		t.Fatalf("clipboard is %q, expected \"copied\"", window.Clipboard)
+	}
+	window.SetTitle("title")
+	if window.Title != "title" {
+		t.Fatalf("title is %q, expected \"title\"", window.Title)
+	}
+}
@@ -0,0 +1,483 @@
+package plugin
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io"
+	"math"
+	"math/big"
+
+	"github.com/pkg/errors"
+)
+
+// Type markers of the values in the standard message format.
+const (
+	standardMessageTypeNull         = 0
+	standardMessageTypeTrue         = 1
+	standardMessageTypeFalse        = 2
+	standardMessageTypeInt32        = 3
+	standardMessageTypeInt64        = 4
+	standardMessageTypeBigInt       = 5
+	standardMessageTypeFloat64      = 6
+	standardMessageTypeString       = 7
+	standardMessageTypeByteSlice    = 8
+	standardMessageTypeInt32Slice   = 9
+	standardMessageTypeInt64Slice   = 10
+	standardMessageTypeFloat64Slice = 11
+	standardMessageTypeList         = 12
+	standardMessageTypeMap          = 13
+)
+
+// The engine and the Dart VM use the host endianness, all the platforms
+// supported by go-flutter are little endian.
+var standardEndian = binary.LittleEndian
+
+// StandardMessageCodec implements a MessageCodec using the Flutter standard
+// binary encoding.
+//
+// This codec tries to stay compatible with the corresponding
+// StandardMessageCodec on the Dart side.
+//
+// Supported messages are acyclic values of these forms:
+//
+//	nil
+//	bool
+//	int32, int64 (int is encoded as int32 when it fits, int64 otherwise)
+//	int8, int16, uint8, uint16, uint32, uint, uint64 (encoded as int)
+//	*big.Int
+//	float64 (float32 is encoded as float64)
+//	string
+//	[]byte, []int32, []int64, []float64
+//	[]interface{} of supported values
+//	map[interface{}]interface{} with supported keys and values
+//
+// On the Dart side, these values are represented as follows:
+//
+//	nil: null
+//	bool: bool
+//	int32, int64: int
+//	*big.Int: BigInt
+//	float64: double
+//	string: String
+//	[]byte: Uint8List
+//	[]int32: Int32List
+//	[]int64: Int64List
+//	[]float64: Float64List
+//	[]interface{}: List
+//	map[interface{}]interface{}: Map
+//
+// Decoding produces the same Go types, int values are decoded as int32 or
+// int64 depending on their size on the wire. Dart has no unsigned integers,
+// encoding an unsigned value larger than math.MaxInt64 returns an error.
+type StandardMessageCodec struct{}
+
+var _ MessageCodec = StandardMessageCodec{} // compile-time type check
+
+// EncodeMessage encodes message to bytes using the Flutter standard message
+// encoding. message is expected to be comprised of supported types.
+func (s StandardMessageCodec) EncodeMessage(message interface{}) ([]byte, error) {
+	if message == nil {
+		return nil, nil
+	}
+
+	var buf bytes.Buffer
+	err := s.writeValue(&buf, message)
+	if err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// DecodeMessage decodes binary data into a standard message.
+func (s StandardMessageCodec) DecodeMessage(data []byte) (interface{}, error) {
+	if len(data) == 0 {
+		return nil, nil
+	}
+
+	buf := bytes.NewReader(data)
+	value, err := s.readValue(buf)
+	if err != nil {
+		return nil, err
+	}
+	if buf.Len() != 0 {
+		return nil, errors.New("invalid standard message: message corrupted, trailing bytes")
+	}
+	return value, nil
+}
+
+// writeSize writes a non-negative size using the variable length encoding
+// of the standard message format.
+func (s StandardMessageCodec) writeSize(buf *bytes.Buffer, size int) error {
+	if size < 0 {
+		return errors.New("invalid size: negative")
+	}
+	switch {
+	case size < 254:
+		return buf.WriteByte(byte(size))
+	case size <= math.MaxUint16:
+		buf.WriteByte(254)
+		return binary.Write(buf, standardEndian, uint16(size))
+	case uint64(size) <= math.MaxUint32:
+		buf.WriteByte(255)
+		return binary.Write(buf, standardEndian, uint32(size))
+	default:
+		return errors.New("invalid size: larger than 32 bits")
+	}
+}
+
+// writeAlignment pads the buffer with zero bytes until its length is a
+// multiple of alignment.
+func (s StandardMessageCodec) writeAlignment(buf *bytes.Buffer, alignment int) {
+	mod := buf.Len() % alignment
+	if mod != 0 {
+		buf.Write(make([]byte, alignment-mod))
+	}
+}
+
+func (s StandardMessageCodec) writeValue(buf *bytes.Buffer, value interface{}) error {
+	switch typedValue := value.(type) {
+	case nil:
+		return buf.WriteByte(standardMessageTypeNull)
+	case bool:
+		if typedValue {
+			return buf.WriteByte(standardMessageTypeTrue)
+		}
+		return buf.WriteByte(standardMessageTypeFalse)
+	case int32:
+		buf.WriteByte(standardMessageTypeInt32)
+		return binary.Write(buf, standardEndian, typedValue)
+	case int64:
+		buf.WriteByte(standardMessageTypeInt64)
+		return binary.Write(buf, standardEndian, typedValue)
+	case int:
+		if typedValue >= math.MinInt32 && typedValue <= math.MaxInt32 {
+			return s.writeValue(buf, int32(typedValue))
+		}
+		return s.writeValue(buf, int64(typedValue))
+	case int8:
+		return s.writeValue(buf, int32(typedValue))
+	case int16:
+		return s.writeValue(buf, int32(typedValue))
+	case uint8:
+		return s.writeValue(buf, int32(typedValue))
+	case uint16:
+		return s.writeValue(buf, int32(typedValue))
+	case uint32:
+		return s.writeValue(buf, uint64(typedValue))
+	case uint:
+		return s.writeValue(buf, uint64(typedValue))
+	case uint64:
+		if typedValue > math.MaxInt64 {
+			return errors.Errorf("unsupported value %d in standard message: unsigned integer larger than int64", typedValue)
+		}
+		if typedValue <= math.MaxInt32 {
+			return s.writeValue(buf, int32(typedValue))
+		}
+		return s.writeValue(buf, int64(typedValue))
+	case *big.Int:
+		if typedValue == nil {
+			return buf.WriteByte(standardMessageTypeNull)
+		}
+		buf.WriteByte(standardMessageTypeBigInt)
+		hex := typedValue.Text(16)
+		err := s.writeSize(buf, len(hex))
+		if err != nil {
+			return err
+		}
+		_, err = buf.WriteString(hex)
+		return err
+	case float32:
+		return s.writeValue(buf, float64(typedValue))
+	case float64:
+		buf.WriteByte(standardMessageTypeFloat64)
+		s.writeAlignment(buf, 8)
+		return binary.Write(buf, standardEndian, typedValue)
+	case string:
+		buf.WriteByte(standardMessageTypeString)
+		err := s.writeSize(buf, len(typedValue))
+		if err != nil {
+			return err
+		}
+		_, err = buf.WriteString(typedValue)
+		return err
+	case []byte:
+		buf.WriteByte(standardMessageTypeByteSlice)
+		err := s.writeSize(buf, len(typedValue))
+		if err != nil {
+			return err
+		}
+		_, err = buf.Write(typedValue)
+		return err
+	case []int32:
+		buf.WriteByte(standardMessageTypeInt32Slice)
+		err := s.writeSize(buf, len(typedValue))
+		if err != nil {
+			return err
+		}
+		s.writeAlignment(buf, 4)
+		return binary.Write(buf, standardEndian, typedValue)
+	case []int64:
+		buf.WriteByte(standardMessageTypeInt64Slice)
+		err := s.writeSize(buf, len(typedValue))
+		if err != nil {
+			return err
+		}
+		s.writeAlignment(buf, 8)
+		return binary.Write(buf, standardEndian, typedValue)
+	case []float64:
+		buf.WriteByte(standardMessageTypeFloat64Slice)
+		err := s.writeSize(buf, len(typedValue))
+		if err != nil {
+			return err
+		}
+		s.writeAlignment(buf, 8)
+		return binary.Write(buf, standardEndian, typedValue)
+	case []interface{}:
+		buf.WriteByte(standardMessageTypeList)
+		err := s.writeSize(buf, len(typedValue))
+		if err != nil {
+			return err
+		}
+		for _, v := range typedValue {
+			err = s.writeValue(buf, v)
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	case map[interface{}]interface{}:
+		buf.WriteByte(standardMessageTypeMap)
+		err := s.writeSize(buf, len(typedValue))
+		if err != nil {
+			return err
+		}
+		for k, v := range typedValue {
+			err = s.writeValue(buf, k)
+			if err != nil {
+				return err
+			}
+			err = s.writeValue(buf, v)
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	default:
+		return errors.Errorf("unsupported value type %T in standard message", value)
+	}
+}
+
+// readSize reads a size encoded by writeSize.
+func (s StandardMessageCodec) readSize(buf *bytes.Reader) (int, error) {
+	b, err := buf.ReadByte()
+	if err != nil {
+		return 0, errors.Wrap(err, "invalid standard message: reading size")
+	}
+	switch b {
+	case 254:
+		var size uint16
+		err = binary.Read(buf, standardEndian, &size)
+		if err != nil {
+			return 0, errors.Wrap(err, "invalid standard message: reading size")
+		}
+		return int(size), nil
+	case 255:
+		var size uint32
+		err = binary.Read(buf, standardEndian, &size)
+		if err != nil {
+			return 0, errors.Wrap(err, "invalid standard message: reading size")
+		}
+		if uint64(size) > uint64(buf.Len()) {
+			return 0, errors.New("invalid standard message: size larger than the message")
+		}
+		return int(size), nil
+	default:
+		return int(b), nil
+	}
+}
+
+// readAlignment skips the padding inserted by writeAlignment.
+func (s StandardMessageCodec) readAlignment(buf *bytes.Reader, alignment int) error {
+	position := int(buf.Size()) - buf.Len()
+	mod := position % alignment
+	if mod != 0 {
+		_, err := buf.Seek(int64(alignment-mod), io.SeekCurrent)
+		if err != nil {
+			return errors.Wrap(err, "invalid standard message: reading alignment")
+		}
+	}
+	return nil
+}
+
+// readBytes reads exactly n bytes from the buffer.
+func (s StandardMessageCodec) readBytes(buf *bytes.Reader, n int) ([]byte, error) {
+	if n > buf.Len() {
+		return nil, errors.New("invalid standard message: unexpected end of message")
+	}
+	data := make([]byte, n)
+	_, err := io.ReadFull(buf, data)
+	if err != nil {
+		return nil, errors.Wrap(err, "invalid standard message")
+	}
+	return data, nil
+}
+
+// readFixed reads fixed size data, checking that the message is long enough
+// to hold it.
+func (s StandardMessageCodec) readFixed(buf *bytes.Reader, data interface{}) error {
+	if binary.Size(data) > buf.Len() {
+		return errors.New("invalid standard message: unexpected end of message")
+	}
+	return binary.Read(buf, standardEndian, data)
+}
+
+func (s StandardMessageCodec) readValue(buf *bytes.Reader) (interface{}, error) {
+	valueType, err := buf.ReadByte()
+	if err != nil {
+		return nil, errors.Wrap(err, "invalid standard message: reading value type")
+	}
+
+	switch valueType {
+	case standardMessageTypeNull:
+		return nil, nil
+	case standardMessageTypeTrue:
+		return true, nil
+	case standardMessageTypeFalse:
+		return false, nil
+	case standardMessageTypeInt32:
+		var value int32
+		err = s.readFixed(buf, &value)
+		return value, err
+	case standardMessageTypeInt64:
+		var value int64
+		err = s.readFixed(buf, &value)
+		return value, err
+	case standardMessageTypeBigInt:
+		size, err := s.readSize(buf)
+		if err != nil {
+			return nil, err
+		}
+		hex, err := s.readBytes(buf, size)
+		if err != nil {
+			return nil, err
+		}
+		value, ok := new(big.Int).SetString(string(hex), 16)
+		if !ok {
+			return nil, errors.Errorf("invalid standard message: invalid big int %q", hex)
+		}
+		return value, nil
+	case standardMessageTypeFloat64:
+		err = s.readAlignment(buf, 8)
+		if err != nil {
+			return nil, err
+		}
+		var value float64
+		err = s.readFixed(buf, &value)
+		return value, err
+	case standardMessageTypeString:
+		size, err := s.readSize(buf)
+		if err != nil {
+			return nil, err
+		}
+		value, err := s.readBytes(buf, size)
+		if err != nil {
+			return nil, err
+		}
+		return string(value), nil
+	case standardMessageTypeByteSlice:
+		size, err := s.readSize(buf)
+		if err != nil {
+			return nil, err
+		}
+		return s.readBytes(buf, size)
+	case standardMessageTypeInt32Slice:
+		size, err := s.readSize(buf)
+		if err != nil {
+			return nil, err
+		}
+		err = s.readAlignment(buf, 4)
+		if err != nil {
+			return nil, err
+		}
+		if size > buf.Len()/4 {
+			return nil, errors.New("invalid standard message: unexpected end of message")
+		}
+		value := make([]int32, size)
+		err = s.readFixed(buf, value)
+		return value, err
+	case standardMessageTypeInt64Slice:
+		size, err := s.readSize(buf)
+		if err != nil {
+			return nil, err
+		}
+		err = s.readAlignment(buf, 8)
+		if err != nil {
+			return nil, err
+		}
+		if size > buf.Len()/8 {
+			return nil, errors.New("invalid standard message: unexpected end of message")
+		}
+		value := make([]int64, size)
+		err = s.readFixed(buf, value)
+		return value, err
+	case standardMessageTypeFloat64Slice:
+		size, err := s.readSize(buf)
+		if err != nil {
+			return nil, err
+		}
+		err = s.readAlignment(buf, 8)
+		if err != nil {
+			return nil, err
+		}
+		if size > buf.Len()/8 {
+			return nil, errors.New("invalid standard message: unexpected end of message")
+		}
+		value := make([]float64, size)
+		err = s.readFixed(buf, value)
+		return value, err
+	case standardMessageTypeList:
+		size, err := s.readSize(buf)
+		if err != nil {
+			return nil, err
+		}
+		if size > buf.Len() {
+			return nil, errors.New("invalid standard message: unexpected end of message")
+		}
+		value := make([]interface{}, size)
+		for i := range value {
+			value[i], err = s.readValue(buf)
+			if err != nil {
+				return nil, err
+			}
+		}
+		return value, nil
+	case standardMessageTypeMap:
+		size, err := s.readSize(buf)
+		if err != nil {
+			return nil, err
+		}
+		if size > buf.Len() {
+			return nil, errors.New("invalid standard message: unexpected end of message")
+		}
+		value := make(map[interface{}]interface{}, size)
+		for i := 0; i < size; i++ {
+			k, err := s.readValue(buf)
+			if err != nil {
+				return nil, err
+			}
+			v, err := s.readValue(buf)
+			if err != nil {
+				return nil, err
+			}
+			// Lists and maps cannot be used as Go map keys.
+			switch k.(type) {
+			case []byte, []int32, []int64, []float64, []interface{}, map[interface{}]interface{}:
+				return nil, errors.Errorf("invalid standard message: unsupported map key type %T", k)
+			}
+			value[k] = v
+		}
+		return value, nil
+	default:
+		return nil, errors.Errorf("invalid standard message: unknown value type %d", valueType)
+	}
+}
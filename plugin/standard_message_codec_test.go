@@ -0,0 +1,316 @@
+package plugin
+
+import (
+	"bytes"
+	"math"
+	"math/big"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func mustBigInt(t *testing.T, s string) *big.Int {
+	t.Helper()
+	value, ok := new(big.Int).SetString(s, 10)
+	if !ok {
+		t.Fatalf("invalid big int %q", s)
+	}
+	return value
+}
+
+// equalValues compares two standard message values, *big.Int values are
+// compared by value.
+func equalValues(a, b interface{}) bool {
+	switch typedA := a.(type) {
+	case *big.Int:
+		typedB, ok := b.(*big.Int)
+		return ok && typedA.Cmp(typedB) == 0
+	case []interface{}:
+		typedB, ok := b.([]interface{})
+		if !ok || len(typedA) != len(typedB) {
+			return false
+		}
+		for i := range typedA {
+			if !equalValues(typedA[i], typedB[i]) {
+				return false
+			}
+		}
+		return true
+	case map[interface{}]interface{}:
+		typedB, ok := b.(map[interface{}]interface{})
+		if !ok || len(typedA) != len(typedB) {
+			return false
+		}
+		for k, v := range typedA {
+			if !equalValues(v, typedB[k]) {
+				return false
+			}
+		}
+		return true
+	default:
+		return reflect.DeepEqual(a, b)
+	}
+}
+
+func TestStandardMessageCodecRoundTrip(t *testing.T) {
+	codec := StandardMessageCodec{}
+	tests := []struct {
+		name  string
+		value interface{}
+	}{
+		{"true", true},
+		{"false", false},
+		{"int32 zero", int32(0)},
+		{"int32 min", int32(math.MinInt32)},
+		{"int32 max", int32(math.MaxInt32)},
+		{"int64 min", int64(math.MinInt64)},
+		{"int64 max", int64(math.MaxInt64)},
+		{"int64 above int32", int64(math.MaxInt32 + 1)},
+		{"bigint", mustBigInt(t, "123456789012345678901234567890")},
+		{"negative bigint", mustBigInt(t, "-123456789012345678901234567890")},
+		{"float64 zero", float64(0)},
+		{"float64", float64(-1.5)},
+		{"float64 max", math.MaxFloat64},
+		{"float64 smallest", math.SmallestNonzeroFloat64},
+		{"float64 infinity", math.Inf(-1)},
+		{"empty string", ""},
+		{"string", "hello"},
+		{"utf-8 string", "héllo wörld ✓"},
+		{"string size 253", strings.Repeat("a", 253)},
+		{"string size 254", strings.Repeat("a", 254)},
+		{"string size 0xffff", strings.Repeat("a", 0xffff)},
+		{"string size 0x10000", strings.Repeat("a", 0x10000)},
+		{"empty byte slice", []byte{}},
+		{"byte slice", []byte{0, 1, 2, 255}},
+		{"byte slice size 0x10000", make([]byte, 0x10000)},
+		{"int32 slice", []int32{math.MinInt32, 0, math.MaxInt32}},
+		{"int64 slice", []int64{math.MinInt64, 0, math.MaxInt64}},
+		{"float64 slice", []float64{-1.5, 0, math.MaxFloat64}},
+		{"empty list", []interface{}{}},
+		{"list", []interface{}{nil, true, int32(1), "a", 1.5, []byte{1}}},
+		{"list size 254", make([]interface{}, 254)},
+		{"nested list", []interface{}{[]interface{}{[]interface{}{"deep"}}}},
+		{"empty map", map[interface{}]interface{}{}},
+		{"map", map[interface{}]interface{}{
+			"a":      int32(1),
+			int32(2): []interface{}{"b"},
+			nil:      map[interface{}]interface{}{true: 2.5},
+		}},
+		// Alignment depends on the position of the value in the message,
+		// the prefixes shift the aligned values by every possible offset.
+		{"aligned float64", []interface{}{"", 1.5}},
+		{"aligned float64 offset", []interface{}{"ab", 1.5, "abc", 2.5}},
+		{"aligned int32 slice", []interface{}{"abc", []int32{1, 2}}},
+		{"aligned int64 slice", []interface{}{"abcde", []int64{1, 2}}},
+		{"aligned float64 slice", []interface{}{"abcdef", []float64{1.5, 2.5}}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			data, err := codec.EncodeMessage(test.value)
+			if err != nil {
+				t.Fatalf("encode: %v", err)
+			}
+			value, err := codec.DecodeMessage(data)
+			if err != nil {
+				t.Fatalf("decode: %v", err)
+			}
+			if !equalValues(test.value, value) {
+				t.Fatalf("decoded %#v, expected %#v", value, test.value)
+			}
+		})
+	}
+}
+
+func TestStandardMessageCodecNil(t *testing.T) {
+	codec := StandardMessageCodec{}
+	data, err := codec.EncodeMessage(nil)
+	if err != nil || len(data) != 0 {
+		t.Fatalf("encode nil: %v, %v", data, err)
+	}
+	value, err := codec.DecodeMessage(nil)
+	if err != nil || value != nil {
+		t.Fatalf("decode empty message: %v, %v", value, err)
+	}
+	value, err = codec.DecodeMessage([]byte{standardMessageTypeNull})
+	if err != nil || value != nil {
+		t.Fatalf("decode null: %v, %v", value, err)
+	}
+}
+
+// TestStandardMessageCodecConversions covers the Go types that are encoded
+// as a different type and thus decode to another Go type.
+func TestStandardMessageCodecConversions(t *testing.T) {
+	codec := StandardMessageCodec{}
+	tests := []struct {
+		name     string
+		value    interface{}
+		expected interface{}
+	}{
+		{"int", int(7), int32(7)},
+		{"int above int32", int(math.MaxInt32 + 1), int64(math.MaxInt32 + 1)},
+		{"int below int32", int(math.MinInt32 - 1), int64(math.MinInt32 - 1)},
+		{"int8", int8(math.MinInt8), int32(math.MinInt8)},
+		{"int16", int16(math.MaxInt16), int32(math.MaxInt16)},
+		{"uint8", uint8(math.MaxUint8), int32(math.MaxUint8)},
+		{"uint16", uint16(math.MaxUint16), int32(math.MaxUint16)},
+		{"uint32", uint32(math.MaxInt32), int32(math.MaxInt32)},
+		{"uint32 above int32", uint32(math.MaxUint32), int64(math.MaxUint32)},
+		{"uint", uint(3), int32(3)},
+		{"uint64 max int64", uint64(math.MaxInt64), int64(math.MaxInt64)},
+		{"float32", float32(1.5), float64(1.5)},
+		{"nil bigint", (*big.Int)(nil), nil},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			data, err := codec.EncodeMessage(test.value)
+			if err != nil {
+				t.Fatalf("encode: %v", err)
+			}
+			value, err := codec.DecodeMessage(data)
+			if err != nil {
+				t.Fatalf("decode: %v", err)
+			}
+			if !reflect.DeepEqual(test.expected, value) {
+				t.Fatalf("decoded %#v (%T), expected %#v (%T)", value, value, test.expected, test.expected)
+			}
+		})
+	}
+}
+
+// TestStandardMessageCodecEncoding checks the wire format against encodings
+// produced by the Dart StandardMessageCodec.
+func TestStandardMessageCodecEncoding(t *testing.T) {
+	codec := StandardMessageCodec{}
+	tests := []struct {
+		name     string
+		value    interface{}
+		expected []byte
+	}{
+		{"true", true, []byte{1}},
+		{"false", false, []byte{2}},
+		{"int32", int32(-2), []byte{3, 0xfe, 0xff, 0xff, 0xff}},
+		{"int64", int64(1) << 32, []byte{4, 0, 0, 0, 0, 1, 0, 0, 0}},
+		{"bigint", big.NewInt(-255), []byte{5, 3, '-', 'f', 'f'}},
+		{"float64", 1.0, []byte{6, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0xf0, 0x3f}},
+		{"string", "ab", []byte{7, 2, 'a', 'b'}},
+		{"byte slice", []byte{9}, []byte{8, 1, 9}},
+		{"int32 slice", []int32{1}, []byte{9, 1, 0, 0, 1, 0, 0, 0}},
+		{"int64 slice", []int64{1}, []byte{10, 1, 0, 0, 0, 0, 0, 0, 1, 0, 0, 0, 0, 0, 0, 0}},
+		{"float64 slice", []float64{1}, []byte{11, 1, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0xf0, 0x3f}},
+		{"list", []interface{}{nil, true}, []byte{12, 2, 0, 1}},
+		{"list float64", []interface{}{1.0}, []byte{12, 1, 6, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0xf0, 0x3f}},
+		{"map", map[interface{}]interface{}{"a": nil}, []byte{13, 1, 7, 1, 'a', 0}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			data, err := codec.EncodeMessage(test.value)
+			if err != nil {
+				t.Fatalf("encode: %v", err)
+			}
+			if !bytes.Equal(data, test.expected) {
+				t.Fatalf("encoded %v, expected %v", data, test.expected)
+			}
+		})
+	}
+}
+
+func TestStandardMessageCodecSizeEncoding(t *testing.T) {
+	codec := StandardMessageCodec{}
+	tests := []struct {
+		size   int
+		prefix []byte
+	}{
+		{0, []byte{8, 0}},
+		{253, []byte{8, 253}},
+		{254, []byte{8, 254, 254, 0}},
+		{0xffff, []byte{8, 254, 0xff, 0xff}},
+		{0x10000, []byte{8, 255, 0, 0, 1, 0}},
+	}
+	for _, test := range tests {
+		data, err := codec.EncodeMessage(make([]byte, test.size))
+		if err != nil {
+			t.Fatalf("size %d: encode: %v", test.size, err)
+		}
+		if !bytes.HasPrefix(data, test.prefix) {
+			t.Fatalf("size %d: encoded prefix %v, expected %v", test.size, data[:len(test.prefix)], test.prefix)
+		}
+		if len(data) != len(test.prefix)+test.size {
+			t.Fatalf("size %d: encoded %d bytes, expected %d", test.size, len(data), len(test.prefix)+test.size)
+		}
+	}
+}
+
+func TestStandardMessageCodecUnsupported(t *testing.T) {
+	codec := StandardMessageCodec{}
+	tests := []struct {
+		name  string
+		value interface{}
+	}{
+		{"uint64 above int64", uint64(math.MaxInt64) + 1},
+		{"struct", struct{}{}},
+		{"string slice", []string{"a"}},
+		{"string map", map[string]interface{}{"a": 1}},
+		{"nested unsupported", []interface{}{struct{}{}}},
+		{"unsupported map value", map[interface{}]interface{}{"a": struct{}{}}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			_, err := codec.EncodeMessage(test.value)
+			if err == nil {
+				t.Fatalf("expected an error encoding %#v", test.value)
+			}
+		})
+	}
+}
+
+func TestStandardMessageCodecMalformed(t *testing.T) {
+	codec := StandardMessageCodec{}
+	tests := []struct {
+		name string
+		data []byte
+	}{
+		{"unknown type", []byte{14}},
+		{"trailing bytes", []byte{1, 1}},
+		{"invalid bigint", []byte{5, 2, 'z', 'z'}},
+		{"size larger than message", []byte{7, 255, 0xff, 0xff, 0xff, 0xff}},
+		{"list larger than message", []byte{12, 254, 0xff, 0xff, 0}},
+		{"map larger than message", []byte{13, 254, 0xff, 0xff, 0}},
+		{"list map key", []byte{13, 1, 12, 0, 0}},
+		{"byte slice map key", []byte{13, 1, 8, 0, 0}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			_, err := codec.DecodeMessage(test.data)
+			if err == nil {
+				t.Fatalf("expected an error decoding %v", test.data)
+			}
+		})
+	}
+}
+
+func TestStandardMessageCodecTruncated(t *testing.T) {
+	codec := StandardMessageCodec{}
+	message := []interface{}{
+		true,
+		int32(1),
+		int64(math.MaxInt64),
+		mustBigInt(t, "123456789012345678901234567890"),
+		1.5,
+		strings.Repeat("a", 300),
+		[]byte{1, 2, 3},
+		[]int32{1, 2},
+		[]int64{1, 2},
+		[]float64{1.5, 2.5},
+		map[interface{}]interface{}{"a": "b"},
+	}
+	data, err := codec.EncodeMessage(message)
+	if err != nil {
+		t.Fatalf("encode: %v", err)
+	}
+	for i := 1; i < len(data); i++ {
+		value, err := codec.DecodeMessage(data[:i])
+		if err == nil {
+			t.Fatalf("expected an error decoding the first %d of %d bytes, got %#v", i, len(data), value)
+		}
+	}
+}
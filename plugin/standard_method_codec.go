@@ -0,0 +1,145 @@
+package plugin
+
+import (
+	"bytes"
+
+	"github.com/pkg/errors"
+)
+
+// StandardMethodCodec implements a MethodCodec using the Flutter standard
+// binary encoding.
+//
+// The arguments and results of the method calls are encoded with the
+// StandardMessageCodec, see its documentation for the supported types.
+type StandardMethodCodec struct {
+	codec StandardMessageCodec
+}
+
+var _ MethodCodec = StandardMethodCodec{} // compile-time type check
+
+// EncodeMethodCall encodes the MethodCall into binary.
+// Returns an error when the arguments are not supported by the
+// StandardMessageCodec.
+func (s StandardMethodCodec) EncodeMethodCall(methodCall MethodCall) ([]byte, error) {
+	var buf bytes.Buffer
+	err := s.codec.writeValue(&buf, methodCall.Method)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed writing methodcall method name")
+	}
+	err = s.codec.writeValue(&buf, methodCall.Arguments)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed writing methodcall arguments")
+	}
+	return buf.Bytes(), nil
+}
+
+// DecodeMethodCall decodes the MethodCall from binary.
+func (s StandardMethodCodec) DecodeMethodCall(data []byte) (MethodCall, error) {
+	buf := bytes.NewReader(data)
+	methodRaw, err := s.codec.readValue(buf)
+	if err != nil {
+		return MethodCall{}, errors.Wrap(err, "failed to decode method name")
+	}
+	method, ok := methodRaw.(string)
+	if !ok {
+		return MethodCall{}, errors.Errorf("invalid method call: method name is %T, expected string", methodRaw)
+	}
+	arguments, err := s.codec.readValue(buf)
+	if err != nil {
+		return MethodCall{}, errors.Wrap(err, "failed to decode method arguments")
+	}
+	if buf.Len() != 0 {
+		return MethodCall{}, errors.New("invalid method call: trailing bytes")
+	}
+	return MethodCall{
+		Method:    method,
+		Arguments: arguments,
+	}, nil
+}
+
+// EncodeSuccessEnvelope encodes a successful result into a binary envelope.
+func (s StandardMethodCodec) EncodeSuccessEnvelope(result interface{}) ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteByte(0)
+	err := s.codec.writeValue(&buf, result)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to encode result")
+	}
+	return buf.Bytes(), nil
+}
+
+// EncodeErrorEnvelope encodes an error result into a binary envelope.
+// message is encoded as null when empty.
+func (s StandardMethodCodec) EncodeErrorEnvelope(code string, message string, details interface{}) ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteByte(1)
+	err := s.codec.writeValue(&buf, code)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to encode error code")
+	}
+	if message == "" {
+		err = s.codec.writeValue(&buf, nil)
+	} else {
+		err = s.codec.writeValue(&buf, message)
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to encode error message")
+	}
+	err = s.codec.writeValue(&buf, details)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to encode error details")
+	}
+	return buf.Bytes(), nil
+}
+
+// DecodeEnvelope decodes a result envelope from binary.
+// An error envelope is returned as a *FlutterError.
+func (s StandardMethodCodec) DecodeEnvelope(envelope []byte) (interface{}, error) {
+	buf := bytes.NewReader(envelope)
+	flag, err := buf.ReadByte()
+	if err != nil {
+		return nil, errors.Wrap(err, "invalid envelope: reading flag")
+	}
+	switch flag {
+	case 0:
+		result, err := s.codec.readValue(buf)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to decode result")
+		}
+		if buf.Len() != 0 {
+			return nil, errors.New("invalid envelope: trailing bytes")
+		}
+		return result, nil
+	case 1:
+		codeRaw, err := s.codec.readValue(buf)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to decode error code")
+		}
+		code, ok := codeRaw.(string)
+		if !ok {
+			return nil, errors.Errorf("invalid envelope: error code is %T, expected string", codeRaw)
+		}
+		messageRaw, err := s.codec.readValue(buf)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to decode error message")
+		}
+		message, ok := messageRaw.(string)
+		if messageRaw != nil && !ok {
+			return nil, errors.Errorf("invalid envelope: error message is %T, expected string", messageRaw)
+		}
+		details, err := s.codec.readValue(buf)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to decode error details")
+		}
+		if buf.Len() != 0 {
+			return nil, errors.New("invalid envelope: trailing bytes")
+		}
+		return nil, &FlutterError{
+			Code:    code,
+			Message: message,
+			Details: details,
+		}
+	default:
+		return nil, errors.Errorf("invalid envelope: unknown flag %d", flag)
+	}
+}
@@ -0,0 +1,166 @@
+package plugin
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+)
+
+func TestStandardMethodCodecMethodCall(t *testing.T) {
+	codec := StandardMethodCodec{}
+	tests := []MethodCall{
+		{Method: "noArguments"},
+		{Method: "string", Arguments: "argument"},
+		{Method: "list", Arguments: []interface{}{int32(1), "b", 2.5}},
+		{Method: "map", Arguments: map[interface{}]interface{}{"key": []int64{1, 2}}},
+	}
+	for _, test := range tests {
+		t.Run(test.Method, func(t *testing.T) {
+			data, err := codec.EncodeMethodCall(test)
+			if err != nil {
+				t.Fatalf("encode: %v", err)
+			}
+			methodCall, err := codec.DecodeMethodCall(data)
+			if err != nil {
+				t.Fatalf("decode: %v", err)
+			}
+			if !reflect.DeepEqual(test, methodCall) {
+				t.Fatalf("decoded %#v, expected %#v", methodCall, test)
+			}
+		})
+	}
+}
+
+func TestStandardMethodCodecMethodCallEncoding(t *testing.T) {
+	codec := StandardMethodCodec{}
+	data, err := codec.EncodeMethodCall(MethodCall{Method: "m", Arguments: true})
+	if err != nil {
+		t.Fatalf("encode: %v", err)
+	}
+	expected := []byte{7, 1, 'm', 1}
+	if !bytes.Equal(data, expected) {
+		t.Fatalf("encoded %v, expected %v", data, expected)
+	}
+}
+
+func TestStandardMethodCodecMalformedMethodCall(t *testing.T) {
+	codec := StandardMethodCodec{}
+	tests := []struct {
+		name string
+		data []byte
+	}{
+		{"empty", []byte{}},
+		{"method name not a string", []byte{3, 1, 0, 0, 0, 0}},
+		{"missing arguments", []byte{7, 1, 'm'}},
+		{"truncated method name", []byte{7, 2, 'm'}},
+		{"trailing bytes", []byte{7, 1, 'm', 0, 0}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			_, err := codec.DecodeMethodCall(test.data)
+			if err == nil {
+				t.Fatalf("expected an error decoding %v", test.data)
+			}
+		})
+	}
+}
+
+func TestStandardMethodCodecSuccessEnvelope(t *testing.T) {
+	codec := StandardMethodCodec{}
+	tests := []struct {
+		name   string
+		result interface{}
+	}{
+		{"nil", nil},
+		{"string", "result"},
+		{"list", []interface{}{int32(1), nil}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			data, err := codec.EncodeSuccessEnvelope(test.result)
+			if err != nil {
+				t.Fatalf("encode: %v", err)
+			}
+			if data[0] != 0 {
+				t.Fatalf("success envelope flag is %d, expected 0", data[0])
+			}
+			result, err := codec.DecodeEnvelope(data)
+			if err != nil {
+				t.Fatalf("decode: %v", err)
+			}
+			if !reflect.DeepEqual(test.result, result) {
+				t.Fatalf("decoded %#v, expected %#v", result, test.result)
+			}
+		})
+	}
+}
+
+func TestStandardMethodCodecErrorEnvelope(t *testing.T) {
+	codec := StandardMethodCodec{}
+	tests := []FlutterError{
+		{Code: "code"},
+		{Code: "code", Message: "message"},
+		{Code: "code", Message: "message", Details: []interface{}{"details"}},
+	}
+	for _, test := range tests {
+		data, err := codec.EncodeErrorEnvelope(test.Code, test.Message, test.Details)
+		if err != nil {
+			t.Fatalf("%v: encode: %v", test, err)
+		}
+		if data[0] != 1 {
+			t.Fatalf("%v: error envelope flag is %d, expected 1", test, data[0])
+		}
+		result, err := codec.DecodeEnvelope(data)
+		if result != nil {
+			t.Fatalf("%v: decoded result %#v, expected nil", test, result)
+		}
+		flutterError, ok := err.(*FlutterError)
+		if !ok {
+			t.Fatalf("%v: decoded error %#v, expected a *FlutterError", test, err)
+		}
+		if !reflect.DeepEqual(*flutterError, test) {
+			t.Fatalf("decoded %#v, expected %#v", *flutterError, test)
+		}
+	}
+}
+
+func TestStandardMethodCodecErrorEnvelopeEncoding(t *testing.T) {
+	codec := StandardMethodCodec{}
+	data, err := codec.EncodeErrorEnvelope("c", "", nil)
+	if err != nil {
+		t.Fatalf("encode: %v", err)
+	}
+	// An empty message is encoded as null, like on the Dart side.
+	expected := []byte{1, 7, 1, 'c', 0, 0}
+	if !bytes.Equal(data, expected) {
+		t.Fatalf("encoded %v, expected %v", data, expected)
+	}
+}
+
+func TestStandardMethodCodecMalformedEnvelope(t *testing.T) {
+	codec := StandardMethodCodec{}
+	tests := []struct {
+		name string
+		data []byte
+	}{
+		{"empty", []byte{}},
+		{"unknown flag", []byte{2, 0}},
+		{"missing result", []byte{0}},
+		{"trailing bytes after result", []byte{0, 0, 0}},
+		{"error code not a string", []byte{1, 0, 0, 0}},
+		{"error message not a string", []byte{1, 7, 1, 'c', 1, 0}},
+		{"missing error details", []byte{1, 7, 1, 'c', 0}},
+		{"trailing bytes after error", []byte{1, 7, 1, 'c', 0, 0, 0}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			_, err := codec.DecodeEnvelope(test.data)
+			if err == nil {
+				t.Fatalf("expected an error decoding %v", test.data)
+			}
+			if _, ok := err.(*FlutterError); ok {
+				t.Fatalf("malformed envelope %v decoded as a FlutterError: %v", test.data, err)
+			}
+		})
+	}
+}
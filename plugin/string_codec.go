@@ -0,0 +1,28 @@
+package plugin
+
+import "github.com/pkg/errors"
+
+// StringCodec implements a MessageCodec using UTF-8 encoded string messages.
+type StringCodec struct{}
+
+var _ MessageCodec = StringCodec{} // compile-time type check
+
+// EncodeMessage encodes a string message, message must be a string.
+func (StringCodec) EncodeMessage(message interface{}) ([]byte, error) {
+	if message == nil {
+		return nil, nil
+	}
+	s, ok := message.(string)
+	if !ok {
+		return nil, errors.Errorf("string codec: expected string, got %T", message)
+	}
+	return []byte(s), nil
+}
+
+// DecodeMessage decodes a binary message into a string.
+func (StringCodec) DecodeMessage(binaryMessage []byte) (interface{}, error) {
+	if binaryMessage == nil {
+		return nil, nil
+	}
+	return string(binaryMessage), nil
+}
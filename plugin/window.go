@@ -0,0 +1,18 @@
+package plugin
+
+// Window defines the window operations available to plugins. It is
+// satisfied by the `*glfw.Window` of the application, and by
+// `plugintest.Window` in tests.
+type Window interface {
+	// SetTitle sets the title of the window.
+	SetTitle(title string)
+	// GetClipboardString returns the content of the clipboard.
+	GetClipboardString() (string, error)
+	// SetClipboardString sets the content of the clipboard.
+	SetClipboardString(str string)
+	// GetSize returns the size of the window, in screen coordinates.
+	GetSize() (width int, height int)
+	// GetFramebufferSize returns the size of the framebuffer of the window,
+	// in pixels.
+	GetFramebufferSize() (width int, height int)
+}
@@ -5,8 +5,10 @@ import (
 	"github.com/go-gl/glfw/v3.2/glfw"
 )
 
-// PluginReceivers do stuff when receiving Message from the Engine,
-// send result with `flutterEngine.SendPlatformMessageResponse`
+// PluginReceivers do stuff when receiving a raw PlatformMessage from the Engine,
+// send result with `flutterEngine.SendPlatformMessageResponse`.
+// The content of the message is left undecoded, PluginReceivers can be used
+// for `BasicMessageChannel` or any other binary protocol.
 type PluginReceivers func(
 	message *embedder.PlatformMessage,
 	flutterEngine *embedder.FlutterEngine,
@@ -0,0 +1,243 @@
+package flutter
+
+import (
+	"time"
+
+	"github.com/go-flutter-desktop/go-flutter/embedder"
+	"github.com/go-gl/glfw/v3.2/glfw"
+)
+
+// mouseDevice is the device identifier of the pointer events sent for the
+// mouse of a window.
+const mouseDevice int32 = 1
+
+// defaultScrollAmount is the distance scrolled for a line of scroll offset,
+// e.g.: a notch of the mouse wheel, in screen coordinates.
+const defaultScrollAmount = 100.0
+
+// mouseButton returns the flutter flag of a glfw mouse button: the button n
+// is the flag 1 << n, e.g.: embedder.PointerButtonMousePrimary for
+// glfw.MouseButtonLeft and embedder.PointerButtonMouseForward for
+// glfw.MouseButton5. The buttons above MouseButton5 are passed as well.
+func mouseButton(button glfw.MouseButton) embedder.PointerButtonMouse {
+	return embedder.PointerButtonMouse(1 << uint(button))
+}
+
+// windowPointer sends the mouse events of a window to the engine. It tracks
+// the pressed buttons to send the phases expected by the engine: KDown for
+// the first pressed button, KUp for the last released one, KMove in between
+// and KHover when no button is pressed. The pointer is removed when the
+// cursor leaves the window and a drag is canceled when the window loses the
+// focus, so that the gestures of the framework never stay down. The
+// callbacks are called by the main thread.
+type windowPointer struct {
+	// sendPointerEvent sends the events, embedder.FlutterEngine.SendPointerEvent
+	// outside of the tests.
+	sendPointerEvent func(event embedder.PointerEvent) embedder.Result
+	transformer      *surfaceTransformer
+	scrollAmount     float64
+
+	added   bool
+	inside  bool
+	buttons embedder.PointerButtonMouse
+}
+
+func newWindowPointer(engine *embedder.FlutterEngine, transformer *surfaceTransformer, scrollAmount float64) *windowPointer {
+	if scrollAmount == 0 {
+		scrollAmount = defaultScrollAmount
+	}
+	return &windowPointer{
+		sendPointerEvent: engine.SendPointerEvent,
+		transformer:      transformer,
+		scrollAmount:     scrollAmount,
+	}
+}
+
+// pointerWindow is the part of *glfw.Window used by the windowPointer.
+type pointerWindow interface {
+	sizedWindow
+	GetCursorPos() (x float64, y float64)
+	GetMouseButton(button glfw.MouseButton) glfw.Action
+}
+
+var _ pointerWindow = &glfw.Window{} // compile-time type check
+
+// glfwMouseButtonCallback is called on mouse button events.
+func (p *windowPointer) glfwMouseButtonCallback(window *glfw.Window, button glfw.MouseButton, action glfw.Action, mods glfw.ModifierKey) {
+	p.handleMouseButton(window, button, action)
+}
+
+func (p *windowPointer) handleMouseButton(window pointerWindow, button glfw.MouseButton, action glfw.Action) {
+	if button < 0 || button > glfw.MouseButtonLast {
+		return
+	}
+	flutterButton := mouseButton(button)
+	phase := embedder.KMove
+	switch action {
+	case glfw.Press:
+		p.inside = true
+		if p.buttons == 0 {
+			phase = embedder.KDown
+		}
+		p.buttons |= flutterButton
+	case glfw.Release:
+		if p.buttons&flutterButton == 0 {
+			// The button was pressed before the pointer was tracked.
+			return
+		}
+		p.buttons &^= flutterButton
+		if p.buttons == 0 {
+			phase = embedder.KUp
+		}
+	default:
+		return
+	}
+	x, y := window.GetCursorPos()
+	p.send(window, phase, x, y)
+	if p.buttons == 0 && !p.inside {
+		// The drag that left the window is over.
+		p.remove(window, x, y)
+	}
+}
+
+// glfwCursorPosCallback is called when the cursor moves, with or without
+// pressed buttons.
+func (p *windowPointer) glfwCursorPosCallback(window *glfw.Window, x float64, y float64) {
+	p.handleCursorPos(window, x, y)
+}
+
+func (p *windowPointer) handleCursorPos(window pointerWindow, x float64, y float64) {
+	if p.buttons == 0 {
+		p.send(window, embedder.KHover, x, y)
+	} else {
+		p.send(window, embedder.KMove, x, y)
+	}
+}
+
+// glfwCursorEnterCallback is called when the cursor enters or leaves the
+// window. The window keeps receiving the events of a drag that leaves it, the
+// pointer is then removed once the buttons are released.
+func (p *windowPointer) glfwCursorEnterCallback(window *glfw.Window, entered bool) {
+	p.handleCursorEnter(window, entered)
+}
+
+func (p *windowPointer) handleCursorEnter(window pointerWindow, entered bool) {
+	p.inside = entered
+	x, y := window.GetCursorPos()
+	if !entered {
+		if p.buttons == 0 {
+			p.remove(window, x, y)
+		}
+		return
+	}
+	p.releaseMissedButtons(window, x, y)
+	if p.buttons == 0 {
+		p.send(window, embedder.KHover, x, y)
+	}
+}
+
+// glfwFocusCallback is called when the window gains or loses the focus. The
+// releases of the buttons happening while the window is not focused may not
+// be received, a drag in progress is canceled.
+func (p *windowPointer) glfwFocusCallback(window *glfw.Window, focused bool) {
+	p.handleFocus(window, focused)
+}
+
+func (p *windowPointer) handleFocus(window pointerWindow, focused bool) {
+	if focused || p.buttons == 0 {
+		return
+	}
+	x, y := window.GetCursorPos()
+	p.buttons = 0
+	p.send(window, embedder.KCancel, x, y)
+	if !p.inside {
+		p.remove(window, x, y)
+	}
+}
+
+// releaseMissedButtons sends the releases of the buttons that happened
+// outside of the window and that were not received.
+func (p *windowPointer) releaseMissedButtons(window pointerWindow, x float64, y float64) {
+	if p.buttons == 0 {
+		return
+	}
+	buttons := p.buttons
+	for button := glfw.MouseButton1; button <= glfw.MouseButtonLast; button++ {
+		if buttons&mouseButton(button) != 0 && window.GetMouseButton(button) == glfw.Release {
+			buttons &^= mouseButton(button)
+		}
+	}
+	if buttons == p.buttons {
+		return
+	}
+	p.buttons = buttons
+	if buttons == 0 {
+		p.send(window, embedder.KUp, x, y)
+	} else {
+		p.send(window, embedder.KMove, x, y)
+	}
+}
+
+// remove removes the pointer from the engine, it is added again by its next
+// event.
+func (p *windowPointer) remove(window pointerWindow, x float64, y float64) {
+	if !p.added {
+		return
+	}
+	p.sendEvent(window, embedder.PointerEvent{Phase: embedder.KRemove}, x, y)
+	p.added = false
+}
+
+// glfwScrollCallback is called on mouse wheel and trackpad scrolls. The
+// offsets are in lines, fractional for the smooth scrolls of trackpads, both
+// are sent as pointer signals.
+func (p *windowPointer) glfwScrollCallback(window *glfw.Window, xoff float64, yoff float64) {
+	p.handleScroll(window, xoff, yoff)
+}
+
+func (p *windowPointer) handleScroll(window pointerWindow, xoff float64, yoff float64) {
+	// The glfw offsets are positive when scrolling up or left, the flutter
+	// deltas are positive when scrolling down or right.
+	dx, dy := windowContentScale(window).toPixels(-xoff*p.scrollAmount, -yoff*p.scrollAmount)
+	dx, dy = p.transformer.deltaToSurface(dx, dy)
+	event := embedder.PointerEvent{
+		Phase:        embedder.KHover,
+		SignalKind:   embedder.PointerSignalKindScroll,
+		ScrollDeltaX: dx,
+		ScrollDeltaY: dy,
+	}
+	if p.buttons != 0 {
+		event.Phase = embedder.KMove
+	}
+	x, y := window.GetCursorPos()
+	p.sendEvent(window, event, x, y)
+}
+
+// send sends a pointer event, the cursor position is in screen coordinates.
+func (p *windowPointer) send(window pointerWindow, phase embedder.PointerPhase, x float64, y float64) {
+	p.sendEvent(window, embedder.PointerEvent{Phase: phase}, x, y)
+}
+
+// sendEvent completes the event with the cursor position, in screen
+// coordinates, and the state of the mouse, then sends it. The pointer is
+// added to the engine before its first event.
+func (p *windowPointer) sendEvent(window pointerWindow, event embedder.PointerEvent, x float64, y float64) {
+	event.X, event.Y = p.transformer.toSurface(windowContentScale(window).toPixels(x, y))
+	event.Timestamp = time.Now().UnixNano() / int64(time.Microsecond)
+	event.Device = mouseDevice
+	event.DeviceKind = embedder.PointerDeviceKindMouse
+
+	if !p.added {
+		p.sendPointerEvent(embedder.PointerEvent{
+			Phase:      embedder.KAdd,
+			Timestamp:  event.Timestamp,
+			X:          event.X,
+			Y:          event.Y,
+			Device:     event.Device,
+			DeviceKind: event.DeviceKind,
+		})
+		p.added = true
+	}
+	event.Buttons = p.buttons
+	p.sendPointerEvent(event)
+}
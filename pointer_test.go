@@ -0,0 +1,294 @@
+package flutter
+
+import (
+	"testing"
+
+	"github.com/go-flutter-desktop/go-flutter/embedder"
+	"github.com/go-gl/glfw/v3.2/glfw"
+)
+
+// expectedEvent is the phase and the pressed buttons of an expected pointer
+// event.
+type expectedEvent struct {
+	phase   embedder.PointerPhase
+	buttons embedder.PointerButtonMouse
+}
+
+// checkEvents checks the phases and the buttons of the sent events.
+func checkEvents(t *testing.T, events []embedder.PointerEvent, expected []expectedEvent) {
+	t.Helper()
+	if len(events) != len(expected) {
+		t.Fatalf("%d events sent, expected %d: %+v", len(events), len(expected), events)
+	}
+	for i, event := range events {
+		if event.Phase != expected[i].phase || event.Buttons != expected[i].buttons {
+			t.Errorf("event %d: phase %d buttons %d, expected phase %d buttons %d",
+				i, event.Phase, event.Buttons, expected[i].phase, expected[i].buttons)
+		}
+	}
+}
+
+func TestMouseButton(t *testing.T) {
+	tests := []struct {
+		button glfw.MouseButton
+		flag   embedder.PointerButtonMouse
+	}{
+		{glfw.MouseButtonLeft, embedder.PointerButtonMousePrimary},
+		{glfw.MouseButtonRight, embedder.PointerButtonMouseSecondary},
+		{glfw.MouseButtonMiddle, embedder.PointerButtonMouseMiddle},
+		{glfw.MouseButton4, embedder.PointerButtonMouseBack},
+		{glfw.MouseButton5, embedder.PointerButtonMouseForward},
+		{glfw.MouseButton6, 1 << 5},
+		{glfw.MouseButtonLast, 1 << 7},
+	}
+	for _, test := range tests {
+		if flag := mouseButton(test.button); flag != test.flag {
+			t.Errorf("button %d mapped to %d, expected %d", test.button, flag, test.flag)
+		}
+	}
+}
+
+func TestPointerButtons(t *testing.T) {
+	secondary := embedder.PointerButtonMouseSecondary
+	middle := embedder.PointerButtonMouseMiddle
+	tests := []struct {
+		name     string
+		actions  func(p *windowPointer, w pointerWindow)
+		expected []expectedEvent
+	}{
+		{
+			name: "secondary click",
+			actions: func(p *windowPointer, w pointerWindow) {
+				p.handleMouseButton(w, glfw.MouseButtonRight, glfw.Press)
+				p.handleMouseButton(w, glfw.MouseButtonRight, glfw.Release)
+			},
+			expected: []expectedEvent{
+				{embedder.KAdd, 0},
+				{embedder.KDown, secondary},
+				{embedder.KUp, 0},
+			},
+		},
+		{
+			name: "secondary and middle",
+			actions: func(p *windowPointer, w pointerWindow) {
+				p.handleMouseButton(w, glfw.MouseButtonRight, glfw.Press)
+				p.handleMouseButton(w, glfw.MouseButtonMiddle, glfw.Press)
+				p.handleCursorPos(w, 100, 40)
+				p.handleMouseButton(w, glfw.MouseButtonRight, glfw.Release)
+				p.handleMouseButton(w, glfw.MouseButtonMiddle, glfw.Release)
+			},
+			expected: []expectedEvent{
+				{embedder.KAdd, 0},
+				{embedder.KDown, secondary},
+				{embedder.KMove, secondary | middle},
+				{embedder.KMove, secondary | middle},
+				{embedder.KMove, middle},
+				{embedder.KUp, 0},
+			},
+		},
+		{
+			name: "hover before press",
+			actions: func(p *windowPointer, w pointerWindow) {
+				p.handleCursorPos(w, 100, 40)
+				p.handleCursorPos(w, 100, 40)
+				p.handleMouseButton(w, glfw.MouseButtonMiddle, glfw.Press)
+				p.handleCursorPos(w, 100, 40)
+				p.handleMouseButton(w, glfw.MouseButtonMiddle, glfw.Release)
+				p.handleCursorPos(w, 100, 40)
+			},
+			expected: []expectedEvent{
+				{embedder.KAdd, 0},
+				{embedder.KHover, 0},
+				{embedder.KHover, 0},
+				{embedder.KDown, middle},
+				{embedder.KMove, middle},
+				{embedder.KUp, 0},
+				{embedder.KHover, 0},
+			},
+		},
+		{
+			name: "release of an untracked press",
+			actions: func(p *windowPointer, w pointerWindow) {
+				p.handleMouseButton(w, glfw.MouseButtonRight, glfw.Release)
+				p.handleCursorPos(w, 100, 40)
+			},
+			expected: []expectedEvent{
+				{embedder.KAdd, 0},
+				{embedder.KHover, 0},
+			},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			window := &scaledWindow{scaleX: 1, scaleY: 1, cursorX: 100, cursorY: 40}
+			var events []embedder.PointerEvent
+			test.actions(testPointer(&events), window)
+			checkEvents(t, events, test.expected)
+			for i, event := range events {
+				if event.Device != mouseDevice || event.DeviceKind != embedder.PointerDeviceKindMouse {
+					t.Errorf("event %d: device %d kind %d, expected device %d kind %d",
+						i, event.Device, event.DeviceKind, mouseDevice, embedder.PointerDeviceKindMouse)
+				}
+			}
+		})
+	}
+}
+
+func TestPointerScroll(t *testing.T) {
+	tests := []struct {
+		name       string
+		options    []Option
+		xoff, yoff float64
+		deltaX     float64
+		deltaY     float64
+		buttonHeld bool
+	}{
+		{name: "wheel down", yoff: -1, deltaY: 100},
+		{name: "wheel up", yoff: 1, deltaY: -100},
+		{name: "wheel left", xoff: 1, deltaX: -100},
+		{name: "wheel right", xoff: -1, deltaX: 100},
+		{name: "trackpad", xoff: 0.25, yoff: -0.5, deltaX: -25, deltaY: 50},
+		{name: "scroll amount", options: []Option{OptionScrollAmount(40)}, xoff: -0.5, yoff: -1, deltaX: 20, deltaY: 40},
+		{name: "inverted scroll amount", options: []Option{OptionScrollAmount(-40)}, xoff: -0.5, yoff: -1, deltaX: -20, deltaY: -40},
+		{name: "button held", yoff: -1, deltaY: 100, buttonHeld: true},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var c config
+			c = c.merge(test.options...)
+			window := &scaledWindow{scaleX: 1, scaleY: 1, cursorX: 100, cursorY: 40}
+			var events []embedder.PointerEvent
+			pointer := newWindowPointer(nil, nil, c.ScrollAmount)
+			pointer.sendPointerEvent = testPointer(&events).sendPointerEvent
+
+			expected := []expectedEvent{{embedder.KAdd, 0}}
+			if test.buttonHeld {
+				pointer.handleMouseButton(window, glfw.MouseButtonLeft, glfw.Press)
+				expected = append(expected,
+					expectedEvent{embedder.KDown, embedder.PointerButtonMousePrimary},
+					expectedEvent{embedder.KMove, embedder.PointerButtonMousePrimary})
+			} else {
+				expected = append(expected, expectedEvent{embedder.KHover, 0})
+			}
+			pointer.handleScroll(window, test.xoff, test.yoff)
+			checkEvents(t, events, expected)
+
+			scroll := events[len(events)-1]
+			if scroll.SignalKind != embedder.PointerSignalKindScroll {
+				t.Errorf("scroll sent with signal %d, expected %d", scroll.SignalKind, embedder.PointerSignalKindScroll)
+			}
+			if scroll.ScrollDeltaX != test.deltaX || scroll.ScrollDeltaY != test.deltaY {
+				t.Errorf("scroll delta (%v, %v), expected (%v, %v)", scroll.ScrollDeltaX, scroll.ScrollDeltaY, test.deltaX, test.deltaY)
+			}
+			if scroll.X != 100 || scroll.Y != 40 {
+				t.Errorf("scroll at (%v, %v), expected (100, 40)", scroll.X, scroll.Y)
+			}
+		})
+	}
+}
+
+func TestPointerFocusAndLeave(t *testing.T) {
+	primary := embedder.PointerButtonMousePrimary
+	tests := []struct {
+		name     string
+		actions  func(p *windowPointer, w pointerWindow)
+		expected []expectedEvent
+	}{
+		{
+			name: "focus lost during a drag",
+			actions: func(p *windowPointer, w pointerWindow) {
+				p.handleMouseButton(w, glfw.MouseButtonLeft, glfw.Press)
+				p.handleFocus(w, false)
+				p.handleCursorPos(w, 100, 40)
+			},
+			expected: []expectedEvent{
+				{embedder.KAdd, 0},
+				{embedder.KDown, primary},
+				{embedder.KCancel, 0},
+				{embedder.KHover, 0},
+			},
+		},
+		{
+			name: "focus lost during a drag outside of the window",
+			actions: func(p *windowPointer, w pointerWindow) {
+				p.handleMouseButton(w, glfw.MouseButtonLeft, glfw.Press)
+				p.handleCursorEnter(w, false)
+				p.handleFocus(w, false)
+				p.handleCursorEnter(w, true)
+			},
+			expected: []expectedEvent{
+				{embedder.KAdd, 0},
+				{embedder.KDown, primary},
+				{embedder.KCancel, 0},
+				{embedder.KRemove, 0},
+				{embedder.KAdd, 0},
+				{embedder.KHover, 0},
+			},
+		},
+		{
+			name: "focus lost and gained without drag",
+			actions: func(p *windowPointer, w pointerWindow) {
+				p.handleCursorPos(w, 100, 40)
+				p.handleFocus(w, false)
+				p.handleFocus(w, true)
+			},
+			expected: []expectedEvent{
+				{embedder.KAdd, 0},
+				{embedder.KHover, 0},
+			},
+		},
+		{
+			name: "cursor leave",
+			actions: func(p *windowPointer, w pointerWindow) {
+				p.handleCursorPos(w, 100, 40)
+				p.handleCursorEnter(w, false)
+				p.handleCursorPos(w, 100, 40)
+			},
+			expected: []expectedEvent{
+				{embedder.KAdd, 0},
+				{embedder.KHover, 0},
+				{embedder.KRemove, 0},
+				{embedder.KAdd, 0},
+				{embedder.KHover, 0},
+			},
+		},
+		{
+			name: "drag released outside of the window",
+			actions: func(p *windowPointer, w pointerWindow) {
+				p.handleMouseButton(w, glfw.MouseButtonLeft, glfw.Press)
+				p.handleCursorEnter(w, false)
+				p.handleCursorPos(w, 100, 40)
+				p.handleMouseButton(w, glfw.MouseButtonLeft, glfw.Release)
+				p.handleCursorEnter(w, true)
+			},
+			expected: []expectedEvent{
+				{embedder.KAdd, 0},
+				{embedder.KDown, primary},
+				{embedder.KMove, primary},
+				{embedder.KUp, 0},
+				{embedder.KRemove, 0},
+				{embedder.KAdd, 0},
+				{embedder.KHover, 0},
+			},
+		},
+		{
+			name: "leave without pointer",
+			actions: func(p *windowPointer, w pointerWindow) {
+				p.handleCursorEnter(w, false)
+			},
+			expected: nil,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			window := &scaledWindow{scaleX: 1, scaleY: 1, cursorX: 100, cursorY: 40}
+			var events []embedder.PointerEvent
+			pointer := testPointer(&events)
+			test.actions(pointer, window)
+			checkEvents(t, events, test.expected)
+			if pointer.buttons != 0 {
+				t.Errorf("buttons %d still pressed", pointer.buttons)
+			}
+		})
+	}
+}
@@ -0,0 +1,91 @@
+package flutter
+
+/*
+#include "gl_procs.h"
+
+// the legacy OpenGL functions used to draw the frames of the software
+// renderer, resolved with glfwGetProcAddress.
+typedef struct {
+	void (APIENTRY *windowPos2i)(int x, int y);
+	void (APIENTRY *pixelZoom)(float xfactor, float yfactor);
+	void (APIENTRY *pixelStorei)(unsigned int pname, int param);
+	void (APIENTRY *drawPixels)(int width, int height, unsigned int format, unsigned int type, const void *pixels);
+} softwareRendererGL;
+
+// drawFrame draws the BGRA pixels in the current context. The rows of the
+// frame go top to bottom, the rows of OpenGL bottom to top.
+static void drawFrame(softwareRendererGL *gl, int width, int height, int rowLength, const void *pixels)
+{
+	gl->windowPos2i(0, height);
+	gl->pixelZoom(1.0f, -1.0f);
+	gl->pixelStorei(GL_UNPACK_ROW_LENGTH, rowLength);
+	gl->drawPixels(width, height, GL_BGRA, GL_UNSIGNED_BYTE, pixels);
+}
+*/
+import "C"
+import (
+	"image"
+	"log"
+	"unsafe"
+
+	"github.com/go-flutter-desktop/go-flutter/internal/embedding"
+	"github.com/go-gl/glfw/v3.2/glfw"
+)
+
+// softwareRenderer draws the frames of the software renderer of the engine
+// in the glfw window. It is only used by the render thread.
+type softwareRenderer struct {
+	window *glfw.Window
+
+	gl       C.softwareRendererGL
+	glLoaded bool
+	glFailed bool
+}
+
+// newSoftwareRenderer returns the renderer presenting the frames in window
+// and to frameCallback, if not nil.
+func newSoftwareRenderer(window *glfw.Window, frameCallback func(frame *image.RGBA)) *embedding.SoftwareRenderer {
+	r := &softwareRenderer{window: window}
+	return &embedding.SoftwareRenderer{
+		Draw:          r.draw,
+		FrameCallback: frameCallback,
+	}
+}
+
+// draw draws the BGRA frame in the window.
+func (r *softwareRenderer) draw(buffer []byte, width int, height int, rowBytes int) {
+	if !r.glLoaded {
+		// The context of the window is only used by the render thread.
+		r.window.MakeContextCurrent()
+		r.loadGL()
+	}
+	if r.glFailed {
+		return
+	}
+	C.drawFrame(&r.gl, C.int(width), C.int(height), C.int(rowBytes/4), unsafe.Pointer(&buffer[0]))
+	r.window.SwapBuffers()
+}
+
+// loadGL resolves the OpenGL functions used to draw the frames. It must be
+// called with the context of the window current.
+func (r *softwareRenderer) loadGL() {
+	r.glLoaded = true
+	procs := []struct {
+		name string
+		proc **[0]byte
+	}{
+		{"glWindowPos2i", &r.gl.windowPos2i},
+		{"glPixelZoom", &r.gl.pixelZoom},
+		{"glPixelStorei", &r.gl.pixelStorei},
+		{"glDrawPixels", &r.gl.drawPixels},
+	}
+	for _, p := range procs {
+		address := glfw.GetProcAddress(p.name)
+		if address == nil {
+			log.Printf("software renderer: OpenGL function %s is unavailable, frames are not drawn in the window\n", p.name)
+			r.glFailed = true
+			return
+		}
+		*p.proc = (*[0]byte)(address)
+	}
+}
@@ -0,0 +1,151 @@
+package flutter
+
+import (
+	"sync"
+
+	"github.com/go-flutter-desktop/go-flutter/embedder"
+)
+
+// SurfaceTransformation describes the transformation applied to the flutter
+// surface, e.g.: to rotate the output for a portrait mounted display. It is
+// only supported by the OpenGL renderer.
+type SurfaceTransformation struct {
+	// Matrix returns the transformation mapping the coordinates of the
+	// flutter surface to the framebuffer of width x height pixels.
+	Matrix func(width int, height int) embedder.Transformation
+	// SwapAxes lays the application out with the width and the height of
+	// the framebuffer swapped, for the 90 and 270 degrees rotations.
+	SwapAxes bool
+}
+
+// Presets of SurfaceTransformation, the rotations are clockwise.
+var (
+	SurfaceRotate90 = SurfaceTransformation{
+		Matrix: func(width int, height int) embedder.Transformation {
+			return embedder.Transformation{SkewX: -1, TransX: float64(width), SkewY: 1, Pers2: 1}
+		},
+		SwapAxes: true,
+	}
+	SurfaceRotate180 = SurfaceTransformation{
+		Matrix: func(width int, height int) embedder.Transformation {
+			return embedder.Transformation{ScaleX: -1, TransX: float64(width), ScaleY: -1, TransY: float64(height), Pers2: 1}
+		},
+	}
+	SurfaceRotate270 = SurfaceTransformation{
+		Matrix: func(width int, height int) embedder.Transformation {
+			return embedder.Transformation{SkewX: 1, SkewY: -1, TransY: float64(height), Pers2: 1}
+		},
+		SwapAxes: true,
+	}
+	SurfaceFlipHorizontal = SurfaceTransformation{
+		Matrix: func(width int, height int) embedder.Transformation {
+			return embedder.Transformation{ScaleX: -1, TransX: float64(width), ScaleY: 1, Pers2: 1}
+		},
+	}
+	SurfaceFlipVertical = SurfaceTransformation{
+		Matrix: func(width int, height int) embedder.Transformation {
+			return embedder.Transformation{ScaleX: 1, ScaleY: -1, TransY: float64(height), Pers2: 1}
+		},
+	}
+)
+
+// SurfaceMatrix returns a SurfaceTransformation applying the same matrix
+// whatever the size of the framebuffer.
+func SurfaceMatrix(matrix embedder.Transformation, swapAxes bool) SurfaceTransformation {
+	return SurfaceTransformation{
+		Matrix: func(width int, height int) embedder.Transformation {
+			return matrix
+		},
+		SwapAxes: swapAxes,
+	}
+}
+
+// surfaceTransformer applies a SurfaceTransformation to the surface and to
+// the pointer coordinates. A nil surfaceTransformer applies none.
+type surfaceTransformer struct {
+	transformation SurfaceTransformation
+
+	lock    sync.Mutex
+	matrix  embedder.Transformation
+	inverse embedder.Transformation
+}
+
+func newSurfaceTransformer(transformation *SurfaceTransformation) *surfaceTransformer {
+	if transformation == nil {
+		return nil
+	}
+	t := &surfaceTransformer{transformation: *transformation}
+	t.resize(0, 0)
+	return t
+}
+
+// resize computes the matrix for the framebuffer of width x height pixels.
+// It must be called by the main thread.
+func (t *surfaceTransformer) resize(width int, height int) {
+	if t == nil {
+		return
+	}
+	matrix := t.transformation.Matrix(width, height)
+	t.lock.Lock()
+	t.matrix = matrix
+	t.inverse = invertAffine(matrix)
+	t.lock.Unlock()
+}
+
+// current returns the matrix of the surface, it is called by the render
+// thread.
+func (t *surfaceTransformer) current() embedder.Transformation {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	return t.matrix
+}
+
+// surfaceSize returns the size of the flutter surface for the framebuffer of
+// width x height pixels.
+func (t *surfaceTransformer) surfaceSize(width int, height int) (int, int) {
+	if t == nil || !t.transformation.SwapAxes {
+		return width, height
+	}
+	return height, width
+}
+
+// toSurface maps a point of the framebuffer to the flutter surface.
+func (t *surfaceTransformer) toSurface(x float64, y float64) (float64, float64) {
+	if t == nil {
+		return x, y
+	}
+	t.lock.Lock()
+	m := t.inverse
+	t.lock.Unlock()
+	return m.ScaleX*x + m.SkewX*y + m.TransX, m.SkewY*x + m.ScaleY*y + m.TransY
+}
+
+// deltaToSurface maps a displacement of the framebuffer, e.g.: a scroll
+// delta, to the flutter surface. The translation is not applied.
+func (t *surfaceTransformer) deltaToSurface(dx float64, dy float64) (float64, float64) {
+	if t == nil {
+		return dx, dy
+	}
+	t.lock.Lock()
+	m := t.inverse
+	t.lock.Unlock()
+	return m.ScaleX*dx + m.SkewX*dy, m.SkewY*dx + m.ScaleY*dy
+}
+
+// invertAffine inverts the affine part of the transformation, the
+// perspective factors are ignored. A singular matrix gives the identity.
+func invertAffine(m embedder.Transformation) embedder.Transformation {
+	det := m.ScaleX*m.ScaleY - m.SkewX*m.SkewY
+	if det == 0 {
+		return embedder.IdentityTransformation
+	}
+	return embedder.Transformation{
+		ScaleX: m.ScaleY / det,
+		SkewX:  -m.SkewX / det,
+		TransX: (m.SkewX*m.TransY - m.ScaleY*m.TransX) / det,
+		SkewY:  -m.SkewY / det,
+		ScaleY: m.ScaleX / det,
+		TransY: (m.SkewY*m.TransX - m.ScaleX*m.TransY) / det,
+		Pers2:  1,
+	}
+}
@@ -0,0 +1,115 @@
+package flutter
+
+import (
+	"math"
+	"testing"
+
+	"github.com/go-flutter-desktop/go-flutter/embedder"
+)
+
+// applyAffine maps a point with the affine part of the transformation.
+func applyAffine(m embedder.Transformation, x float64, y float64) (float64, float64) {
+	return m.ScaleX*x + m.SkewX*y + m.TransX, m.SkewY*x + m.ScaleY*y + m.TransY
+}
+
+func almostEqual(a float64, b float64) bool {
+	return math.Abs(a-b) < 1e-9
+}
+
+// testPoints are the points mapped by the tests, with unequal coordinates
+// to catch the swapped axes.
+var testPoints = [][2]float64{{0, 0}, {10, 20}, {800, 0}, {0, 600}, {123.5, 456.25}}
+
+func TestInvertAffine(t *testing.T) {
+	tests := []struct {
+		name   string
+		matrix embedder.Transformation
+	}{
+		{"identity", embedder.IdentityTransformation},
+		{"scale", embedder.Transformation{ScaleX: 2, ScaleY: 0.5, Pers2: 1}},
+		{"translation", embedder.Transformation{ScaleX: 1, ScaleY: 1, TransX: 30, TransY: -40, Pers2: 1}},
+		{"rotation", embedder.Transformation{SkewX: -1, SkewY: 1, TransX: 800, Pers2: 1}},
+		{"shear", embedder.Transformation{ScaleX: 1, SkewX: 0.5, ScaleY: 1, TransY: 3, Pers2: 1}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			inverse := invertAffine(test.matrix)
+			for _, p := range testPoints {
+				x, y := applyAffine(test.matrix, p[0], p[1])
+				x, y = applyAffine(inverse, x, y)
+				if !almostEqual(x, p[0]) || !almostEqual(y, p[1]) {
+					t.Errorf("(%v, %v) mapped back to (%v, %v)", p[0], p[1], x, y)
+				}
+			}
+		})
+	}
+
+	singular := embedder.Transformation{ScaleX: 1, SkewX: 2, SkewY: 1, ScaleY: 2, Pers2: 1}
+	if inverse := invertAffine(singular); inverse != embedder.IdentityTransformation {
+		t.Errorf("singular matrix inverted to %+v, expected the identity", inverse)
+	}
+}
+
+func TestSurfacePresets(t *testing.T) {
+	// Where the top left corner of the surface is drawn in a 800x600
+	// framebuffer, and the size of the surface.
+	tests := []struct {
+		name           string
+		transformation SurfaceTransformation
+		originX        float64
+		originY        float64
+		width          int
+		height         int
+	}{
+		{"rotate 90", SurfaceRotate90, 800, 0, 600, 800},
+		{"rotate 180", SurfaceRotate180, 800, 600, 800, 600},
+		{"rotate 270", SurfaceRotate270, 0, 600, 600, 800},
+		{"flip horizontal", SurfaceFlipHorizontal, 800, 0, 800, 600},
+		{"flip vertical", SurfaceFlipVertical, 0, 600, 800, 600},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			transformer := newSurfaceTransformer(&test.transformation)
+			transformer.resize(800, 600)
+
+			width, height := transformer.surfaceSize(800, 600)
+			if width != test.width || height != test.height {
+				t.Errorf("surface %dx%d, expected %dx%d", width, height, test.width, test.height)
+			}
+			x, y := applyAffine(transformer.current(), 0, 0)
+			if !almostEqual(x, test.originX) || !almostEqual(y, test.originY) {
+				t.Errorf("surface origin drawn at (%v, %v), expected (%v, %v)", x, y, test.originX, test.originY)
+			}
+			// The pointer mapping is the inverse of the surface one.
+			for _, p := range testPoints {
+				x, y := transformer.toSurface(applyAffine(transformer.current(), p[0], p[1]))
+				if !almostEqual(x, p[0]) || !almostEqual(y, p[1]) {
+					t.Errorf("(%v, %v) mapped back to (%v, %v)", p[0], p[1], x, y)
+				}
+			}
+		})
+	}
+}
+
+func TestSurfaceRotate90Pointer(t *testing.T) {
+	transformer := newSurfaceTransformer(&SurfaceRotate90)
+	transformer.resize(800, 600)
+
+	// The top right corner of the framebuffer is the top left one of the
+	// surface.
+	x, y := transformer.toSurface(790, 10)
+	if !almostEqual(x, 10) || !almostEqual(y, 10) {
+		t.Errorf("(790, 10) mapped to (%v, %v), expected (10, 10)", x, y)
+	}
+	// Scrolling down the framebuffer scrolls right the surface laid out
+	// sideways, the translation is not applied to the deltas.
+	dx, dy := transformer.deltaToSurface(0, 5)
+	if !almostEqual(dx, 5) || !almostEqual(dy, 0) {
+		t.Errorf("delta (0, 5) mapped to (%v, %v), expected (5, 0)", dx, dy)
+	}
+
+	var none *surfaceTransformer
+	if x, y := none.toSurface(10, 20); x != 10 || y != 20 {
+		t.Errorf("nil transformer mapped (10, 20) to (%v, %v)", x, y)
+	}
+}
@@ -2,19 +2,21 @@ package flutter
 
 import (
 	"encoding/json"
+	"log"
 
-	"github.com/go-flutter-desktop/go-flutter/embedder"
-	"github.com/go-gl/glfw/v3.2/glfw"
+	"github.com/go-flutter-desktop/go-flutter/internal/systemchannels"
+	"github.com/go-flutter-desktop/go-flutter/plugin"
+	"github.com/pkg/errors"
 )
 
 // Talks to the dart side
 // https://github.com/flutter/flutter/blob/master/packages/flutter/lib/src/services/system_channels.dart
 
-////////////////////
-//  Window Title  //
-////////////////////
+////////////////////////////////
+//  Window Title & Clipboard  //
+////////////////////////////////
 
-// const for `addHandlerWindowTitle`
+// const for `platformPlugin`
 const (
 	// Channel
 	platformChannel = "flutter/platform"
@@ -31,124 +33,250 @@ type ArgsAppSwitcherDescription struct {
 	PrimaryColor int64  `json:"primaryColor"`
 }
 
-func addHandlerWindowTitle() Option {
-	var handler PluginReceivers = func(
-		platMessage *embedder.PlatformMessage,
-		flutterEngine *embedder.FlutterEngine,
-		window *glfw.Window,
-	) bool {
-		message := &platMessage.Message
+// platformPlugin implements the window title and the clipboard.
+type platformPlugin struct {
+	window  plugin.Window
+	channel *plugin.MethodChannel
+}
 
-		if message.Method == setDescriptionMethod {
-			msgBody := ArgsAppSwitcherDescription{}
-			json.Unmarshal(message.Args, &msgBody)
-			window.SetTitle(msgBody.Label)
-			return true
-		}
-		return false
-	}
+var _ PluginWindow = &platformPlugin{} // compile-time type check
 
-	return OptionAddPluginReceiver(handler, platformChannel)
+func (p *platformPlugin) InitPlugin(messenger plugin.BinaryMessenger) error {
+	p.channel = plugin.NewMethodChannel(messenger, platformChannel, plugin.JSONMethodCodec{})
+	p.channel.HandleFunc(setDescriptionMethod, p.handleWindowSetTitle)
+	p.channel.HandleFunc(clipboardSetData, p.handleClipboardSetData)
+	p.channel.HandleFunc(clipboardGetData, p.handleClipboardGetData)
+	return nil
 }
 
-func addHandlerClipboard() Option {
-	handler := func(platMessage *embedder.PlatformMessage,
-		flutterEngine *embedder.FlutterEngine,
-		window *glfw.Window) bool {
-
-		message := &platMessage.Message
-		switch message.Method {
-		case clipboardSetData:
-			newClipboard := struct {
-				Text string `json:"text"`
-			}{}
-			json.Unmarshal(message.Args, &newClipboard)
-			window.SetClipboardString(newClipboard.Text)
-		case clipboardGetData:
-			requestedMime := ""
-			json.Unmarshal(message.Args, &requestedMime)
-			if requestedMime == "text/plain" {
-				clipText, _ := window.GetClipboardString()
+func (p *platformPlugin) InitPluginWindow(window plugin.Window) error {
+	p.window = window
+	return nil
+}
 
-				retBytes, _ := json.Marshal([]struct {
-					Text string `json:"text"`
-				}{{clipText}})
+func (p *platformPlugin) handleWindowSetTitle(arguments interface{}) (reply interface{}, err error) {
+	msgBody := ArgsAppSwitcherDescription{}
+	err = json.Unmarshal(arguments.(json.RawMessage), &msgBody)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to decode arguments")
+	}
+	p.window.SetTitle(msgBody.Label)
+	return nil, nil
+}
 
-				flutterEngine.SendPlatformMessageResponse(platMessage, retBytes)
-				return true
-			} else {
-				// log.Printf("Don't know how to acquire type #v from the clipboard", requestedMime)
-			}
+func (p *platformPlugin) handleClipboardSetData(arguments interface{}) (reply interface{}, err error) {
+	newClipboard := struct {
+		Text string `json:"text"`
+	}{}
+	err = json.Unmarshal(arguments.(json.RawMessage), &newClipboard)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to decode arguments")
+	}
+	p.window.SetClipboardString(newClipboard.Text)
+	return nil, nil
+}
 
-		default:
-			// log.Printf("unhandled platform method: %#v\n", platMessage.Message)
-		}
-		return false
+func (p *platformPlugin) handleClipboardGetData(arguments interface{}) (reply interface{}, err error) {
+	requestedMime := ""
+	err = json.Unmarshal(arguments.(json.RawMessage), &requestedMime)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to decode arguments")
+	}
+	if requestedMime != "text/plain" {
+		// log.Printf("Don't know how to acquire type #v from the clipboard", requestedMime)
+		return nil, nil
+	}
 
+	clipText, err := p.window.GetClipboardString()
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to get the clipboard content")
 	}
-	return OptionAddPluginReceiver(handler, platformChannel)
+	return struct {
+		Text string `json:"text"`
+	}{clipText}, nil
 }
 
 /////////////////
 //  TextInput  //
 /////////////////
 
-// const for `addHandlerTextInput`
-const (
-	// channel
-	textInputChannel = "flutter/textinput"
+// textinputPlugin keeps the text model in sync with the text fields of the
+// flutter application. The keyboard events are handled by `glfwKey`.
+type textinputPlugin struct {
+	channel *plugin.MethodChannel
+}
 
-	// Args -> struct argsEditingState
-	textUpdateStateMethod = "TextInputClient.updateEditingState"
+// defaultTextinputPlugin is the textinputPlugin used by the text model
+// `state`
+var defaultTextinputPlugin = &textinputPlugin{}
 
-	// text
-	textInputClientSet    = "TextInput.setClient"
-	textInputClientClear  = "TextInput.clearClient"
-	textInputSetEditState = "TextInput.setEditingState"
-)
+var _ Plugin = &textinputPlugin{} // compile-time type check
+
+func (p *textinputPlugin) InitPlugin(messenger plugin.BinaryMessenger) error {
+	p.channel = plugin.NewMethodChannel(messenger, systemchannels.TextInputChannel, plugin.JSONMethodCodec{})
+	p.channel.HandleFunc(systemchannels.TextInputClientClear, p.handleClearClient)
+	p.channel.HandleFunc(systemchannels.TextInputClientSet, p.handleSetClient)
+	p.channel.HandleFunc(systemchannels.TextInputSetEditState, p.handleSetEditingState)
+	// No virtual keyboard on the desktop.
+	p.channel.HandleFunc(systemchannels.TextInputShow, func(arguments interface{}) (reply interface{}, err error) { return nil, nil })
+	p.channel.HandleFunc(systemchannels.TextInputHide, func(arguments interface{}) (reply interface{}, err error) { return nil, nil })
+
+	state.notifyState = p.updateEditingState
+	return nil
+}
+
+func (p *textinputPlugin) handleClearClient(arguments interface{}) (reply interface{}, err error) {
+	state.clientID = 0
+	return nil, nil
+}
+
+func (p *textinputPlugin) handleSetClient(arguments interface{}) (reply interface{}, err error) {
+	var body []interface{}
+	err = json.Unmarshal(arguments.(json.RawMessage), &body)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to decode arguments")
+	}
+	if len(body) == 0 {
+		return nil, errors.New("failed to decode arguments: missing client id")
+	}
+	clientID, ok := body[0].(float64)
+	if !ok {
+		return nil, errors.Errorf("failed to decode arguments: client id is %T", body[0])
+	}
+	state.clientID = clientID
+	return nil, nil
+}
+
+func (p *textinputPlugin) handleSetEditingState(arguments interface{}) (reply interface{}, err error) {
+	if state.clientID == 0 {
+		return nil, nil
+	}
+	editingState := systemchannels.EditingState{}
+	err = json.Unmarshal(arguments.(json.RawMessage), &editingState)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to decode arguments")
+	}
+	state.word = []rune(editingState.Text)
+	state.selectionBase = editingState.SelectionBase
+	state.selectionExtent = editingState.SelectionExtent
+	return nil, nil
+}
+
+// updateEditingState updates the TextInput with the current state
+func (p *textinputPlugin) updateEditingState() {
+	editingState := systemchannels.EditingState{
+		Text:                   string(state.word),
+		SelectionAffinity:      "TextAffinity.downstream",
+		SelectionBase:          state.selectionBase,
+		SelectionExtent:        state.selectionExtent,
+		SelectionIsDirectional: false,
+	}
 
-// argsEditingState Args content
-// To update the embedder text use `flutter.SendPlatformMessage` whenever a keys is pressed
-type argsEditingState struct {
-	Text                   string `json:"text"`
-	SelectionBase          int    `json:"selectionBase"`
-	SelectionExtent        int    `json:"selectionExtent"`
-	SelectionAffinity      string `json:"selectionAffinity"`
-	SelectionIsDirectional bool   `json:"selectionIsDirectional"`
-	ComposingBase          int    `json:"composingBase"`
-	ComposingExtent        int    `json:"composingExtent"`
-}
-
-func addHandlerTextInput() Option {
-	var handler PluginReceivers = func(
-		platMessage *embedder.PlatformMessage,
-		flutterEngine *embedder.FlutterEngine,
-		window *glfw.Window,
-	) bool {
-
-		message := &platMessage.Message
-
-		switch message.Method {
-		case textInputClientClear:
-			state.clientID = 0
-		case textInputClientSet:
-			var body []interface{}
-			json.Unmarshal(message.Args, &body)
-			state.clientID = body[0].(float64)
-		case textInputSetEditState:
-			if state.clientID != 0 {
-				editingState := argsEditingState{}
-				json.Unmarshal(message.Args, &editingState)
-				state.word = []rune(editingState.Text)
-				state.selectionBase = editingState.SelectionBase
-				state.selectionExtent = editingState.SelectionExtent
-			}
-		default:
-		}
-
-		return true
-
-	}
-
-	return OptionAddPluginReceiver(handler, textInputChannel)
+	err := p.channel.InvokeMethod(systemchannels.TextUpdateStateMethod, []interface{}{
+		state.clientID,
+		editingState,
+	})
+	if err != nil {
+		log.Printf("failed to update the editing state: %v\n", err)
+	}
+}
+
+func (p *textinputPlugin) performAction(action string) {
+	err := p.channel.InvokeMethod(systemchannels.TextPerformAction, []interface{}{
+		state.clientID,
+		"TextInputAction." + action,
+	})
+	if err != nil {
+		log.Printf("failed to perform the text input action %s: %v\n", action, err)
+	}
+}
+
+////////////////
+//  KeyEvent  //
+////////////////
+
+// keyeventPlugin sends the raw key events used by RawKeyboardListener. The
+// keyboard events are handled by `glfwKey`.
+//
+// glfw gives the character typed by a key press to the char callback, after
+// the key callback: the press is held until its character is known, or
+// until the events of the window have been processed. The release of a key
+// carries the character of its press.
+type keyeventPlugin struct {
+	messenger plugin.BinaryMessenger
+
+	// only used by the main thread
+	pending *systemchannels.KeyEvent
+	chars   map[int]rune
+}
+
+// defaultKeyeventPlugin is the keyeventPlugin used by `glfwKey`
+var defaultKeyeventPlugin = &keyeventPlugin{}
+
+var _ Plugin = &keyeventPlugin{} // compile-time type check
+
+func (p *keyeventPlugin) InitPlugin(messenger plugin.BinaryMessenger) error {
+	p.messenger = messenger
+	return nil
+}
+
+// handleKeyEvent handles a key event, the key code, scan code and modifiers
+// are the glfw ones.
+func (p *keyeventPlugin) handleKeyEvent(eventType string, keyCode int, scanCode int, modifiers int) {
+	p.flushKeyEvent()
+	event := systemchannels.KeyEvent{
+		Type:      eventType,
+		KeyCode:   keyCode,
+		ScanCode:  scanCode,
+		Modifiers: modifiers,
+	}
+	if eventType == systemchannels.KeyEventUp {
+		event.UnicodeScalarValues = p.chars[keyCode]
+		delete(p.chars, keyCode)
+		p.sendKeyEvent(event)
+		return
+	}
+	p.pending = &event
+}
+
+// handleChar sets the character typed by the pending key press, as given by
+// the glfw char callback, and sends the press.
+func (p *keyeventPlugin) handleChar(char rune) {
+	if p.pending == nil {
+		return
+	}
+	if p.chars == nil {
+		p.chars = make(map[int]rune)
+	}
+	p.pending.UnicodeScalarValues = char
+	p.chars[p.pending.KeyCode] = char
+	p.flushKeyEvent()
+}
+
+// flushKeyEvent sends the pending key press, it must be called once the
+// events of the window have been processed. The keys that don't type a
+// character are sent without one.
+func (p *keyeventPlugin) flushKeyEvent() {
+	if p.pending == nil {
+		return
+	}
+	event := *p.pending
+	p.pending = nil
+	p.sendKeyEvent(event)
+}
+
+// sendKeyEvent sends a key event, in the glfw keymap.
+func (p *keyeventPlugin) sendKeyEvent(event systemchannels.KeyEvent) {
+	if p.messenger == nil {
+		return
+	}
+	message, err := systemchannels.EncodeKeyEvent(event)
+	if err != nil {
+		log.Printf("failed to encode the key event: %v\n", err)
+		return
+	}
+	err = p.messenger.Send(systemchannels.KeyEventChannel, message)
+	if err != nil {
+		log.Printf("failed to send the key event: %v\n", err)
+	}
 }
@@ -0,0 +1,53 @@
+package flutter
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/go-flutter-desktop/go-flutter/internal/systemchannels"
+	"github.com/go-flutter-desktop/go-flutter/plugin/plugintest"
+)
+
+func TestKeyeventCharacters(t *testing.T) {
+	messenger := plugintest.NewMessenger()
+	p := &keyeventPlugin{}
+	err := p.InitPlugin(messenger)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// shift+a types "A", the key callback is called before the char one
+	p.handleKeyEvent(systemchannels.KeyEventDown, 65, 38, 1)
+	p.handleChar('A')
+	// the right arrow types nothing
+	p.handleKeyEvent(systemchannels.KeyEventDown, 262, 114, 0)
+	p.flushKeyEvent()
+	p.handleKeyEvent(systemchannels.KeyEventUp, 262, 114, 0)
+	p.handleKeyEvent(systemchannels.KeyEventUp, 65, 38, 0)
+
+	expected := []systemchannels.KeyEvent{
+		{Type: systemchannels.KeyEventDown, KeyCode: 65, ScanCode: 38, Modifiers: 1, UnicodeScalarValues: 'A'},
+		{Type: systemchannels.KeyEventDown, KeyCode: 262, ScanCode: 114},
+		{Type: systemchannels.KeyEventUp, KeyCode: 262, ScanCode: 114},
+		{Type: systemchannels.KeyEventUp, KeyCode: 65, ScanCode: 38, UnicodeScalarValues: 'A'},
+	}
+	sent := messenger.Sent()
+	if len(sent) != len(expected) {
+		t.Fatalf("%d key events sent, expected %d", len(sent), len(expected))
+	}
+	for i, message := range sent {
+		if message.Channel != systemchannels.KeyEventChannel {
+			t.Fatalf("key event sent on %s, expected %s", message.Channel, systemchannels.KeyEventChannel)
+		}
+		var event systemchannels.KeyEvent
+		err = json.Unmarshal(message.Data, &event)
+		if err != nil {
+			t.Fatal(err)
+		}
+		expected[i].Keymap = "linux"
+		expected[i].Toolkit = "glfw"
+		if event != expected[i] {
+			t.Errorf("key event %d: %+v, expected %+v", i, event, expected[i])
+		}
+	}
+}
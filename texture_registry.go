@@ -0,0 +1,325 @@
+package flutter
+
+/*
+#include "gl_procs.h"
+
+#define GL_TEXTURE_BINDING_2D 0x8069
+
+// the OpenGL functions used to upload the frames of the external textures,
+// resolved with glfwGetProcAddress.
+typedef struct {
+	void (APIENTRY *genTextures)(int n, unsigned int *textures);
+	void (APIENTRY *deleteTextures)(int n, const unsigned int *textures);
+	void (APIENTRY *bindTexture)(unsigned int target, unsigned int texture);
+	void (APIENTRY *texParameteri)(unsigned int target, unsigned int pname, int param);
+	void (APIENTRY *pixelStorei)(unsigned int pname, int param);
+	void (APIENTRY *getIntegerv)(unsigned int pname, int *data);
+	void (APIENTRY *texImage2D)(unsigned int target, int level, int internalformat, int width, int height,
+				    int border, unsigned int format, unsigned int type, const void *pixels);
+	void (APIENTRY *texSubImage2D)(unsigned int target, int level, int xoffset, int yoffset, int width, int height,
+				       unsigned int format, unsigned int type, const void *pixels);
+} textureRegistryGL;
+
+// uploadTexture uploads the RGBA pixels to the texture, created when name is
+// 0. The storage of the texture is allocated when allocate is set, e.g.: for
+// the first frame or a frame of another size, otherwise the pixels replace
+// those of the previous frame. The GL state used by the engine is restored.
+// It returns the name of the texture.
+static unsigned int uploadTexture(textureRegistryGL *gl, unsigned int name, int allocate, int width, int height,
+				  int rowLength, const void *pixels)
+{
+	int previousTexture, previousRowLength;
+	gl->getIntegerv(GL_TEXTURE_BINDING_2D, &previousTexture);
+	gl->getIntegerv(GL_UNPACK_ROW_LENGTH, &previousRowLength);
+
+	if (name == 0)
+	{
+		gl->genTextures(1, &name);
+		gl->bindTexture(GL_TEXTURE_2D, name);
+		gl->texParameteri(GL_TEXTURE_2D, GL_TEXTURE_MIN_FILTER, GL_LINEAR);
+		gl->texParameteri(GL_TEXTURE_2D, GL_TEXTURE_MAG_FILTER, GL_LINEAR);
+		gl->texParameteri(GL_TEXTURE_2D, GL_TEXTURE_WRAP_S, GL_CLAMP_TO_EDGE);
+		gl->texParameteri(GL_TEXTURE_2D, GL_TEXTURE_WRAP_T, GL_CLAMP_TO_EDGE);
+	}
+	else
+	{
+		gl->bindTexture(GL_TEXTURE_2D, name);
+	}
+	gl->pixelStorei(GL_UNPACK_ROW_LENGTH, rowLength);
+	if (allocate)
+	{
+		gl->texImage2D(GL_TEXTURE_2D, 0, GL_RGBA8, width, height, 0, GL_RGBA, GL_UNSIGNED_BYTE, pixels);
+	}
+	else
+	{
+		gl->texSubImage2D(GL_TEXTURE_2D, 0, 0, 0, width, height, GL_RGBA, GL_UNSIGNED_BYTE, pixels);
+	}
+
+	gl->pixelStorei(GL_UNPACK_ROW_LENGTH, previousRowLength);
+	gl->bindTexture(GL_TEXTURE_2D, previousTexture);
+	return name;
+}
+
+static void deleteTexture(textureRegistryGL *gl, unsigned int name)
+{
+	gl->deleteTextures(1, &name);
+}
+*/
+import "C"
+import (
+	"image"
+	"log"
+	"sync"
+	"unsafe"
+
+	"github.com/go-flutter-desktop/go-flutter/embedder"
+	"github.com/go-flutter-desktop/go-flutter/internal/taskqueue"
+	"github.com/pkg/errors"
+)
+
+// TextureSource produces the RGBA frames of an external texture.
+type TextureSource interface {
+	// TextureFrame returns the frame of the texture, which is drawn at
+	// width x height pixels. It is called by the render thread of the engine
+	// after the texture has been marked with FrameAvailable. A nil frame
+	// keeps the previous one.
+	TextureFrame(width int, height int) *image.RGBA
+}
+
+// TextureRegistry registers the external textures drawn by the `Texture`
+// widget of the dart side, e.g.: video or camera frames. The frames are
+// uploaded to OpenGL textures, external textures are not supported by the
+// software renderer: Register returns an error when the application is run
+// with OptionSoftwareRenderer.
+//
+// Plugins get the registry by implementing PluginTexture.
+type TextureRegistry struct {
+	engine *embedder.FlutterEngine
+	tasks  *taskqueue.Queue
+	// softwareRenderer is true when the application doesn't render with
+	// OpenGL, the textures can't be uploaded.
+	softwareRenderer bool
+
+	lock     sync.Mutex
+	lastID   int64
+	textures map[int64]*registeredTexture
+	// deleted holds the GL textures of the unregistered textures, they are
+	// deleted by the render thread.
+	deleted []uint32
+
+	// only used by the render thread
+	glProcResolver func(procName string) unsafe.Pointer
+	gl             C.textureRegistryGL
+	glLoaded       bool
+	glFailed       bool
+}
+
+// registeredTexture is a texture known by the TextureRegistry, name is the
+// GL texture once the first frame has been uploaded and width and height the
+// size of its storage.
+type registeredTexture struct {
+	source TextureSource
+	name   uint32
+	width  int
+	height int
+}
+
+// Texture is an external texture registered with a TextureRegistry.
+type Texture struct {
+	// ID is the identifier of the texture, given to the `Texture` widget.
+	ID       int64
+	registry *TextureRegistry
+}
+
+func newTextureRegistry(engine *embedder.FlutterEngine, tasks *taskqueue.Queue, glProcResolver func(procName string) unsafe.Pointer, softwareRenderer bool) *TextureRegistry {
+	return &TextureRegistry{
+		engine:           engine,
+		tasks:            tasks,
+		softwareRenderer: softwareRenderer,
+		textures:         make(map[int64]*registeredTexture),
+		glProcResolver:   glProcResolver,
+	}
+}
+
+// Register registers an external texture. Its first frame is requested once
+// FrameAvailable is called. It is safe to call from any goroutine. It
+// returns an error with the software renderer.
+func (r *TextureRegistry) Register(source TextureSource) (*Texture, error) {
+	if source == nil {
+		return nil, errors.New("failed to register texture: nil source")
+	}
+	if r.softwareRenderer {
+		return nil, errors.New("failed to register texture: external textures are not supported by the software renderer")
+	}
+	r.lock.Lock()
+	r.lastID++
+	id := r.lastID
+	r.textures[id] = &registeredTexture{source: source}
+	r.lock.Unlock()
+
+	err := r.tasks.Post(taskqueue.Task{Fn: func() {
+		res := r.engine.RegisterExternalTexture(id)
+		if res != embedder.KSuccess {
+			log.Printf("failed to register texture %d: engine result %d\n", id, res)
+		}
+	}})
+	if err != nil {
+		r.lock.Lock()
+		delete(r.textures, id)
+		r.lock.Unlock()
+		return nil, errors.Wrap(err, "failed to register texture")
+	}
+	return &Texture{ID: id, registry: r}, nil
+}
+
+// FrameAvailable tells the engine that the source of the texture has a new
+// frame. It is safe to call from any goroutine. It returns an error once the
+// texture is unregistered.
+func (t *Texture) FrameAvailable() error {
+	t.registry.lock.Lock()
+	_, ok := t.registry.textures[t.ID]
+	t.registry.lock.Unlock()
+	if !ok {
+		return errors.Errorf("failed to mark frame available on texture %d: the texture is not registered", t.ID)
+	}
+
+	err := t.registry.tasks.Post(taskqueue.Task{Fn: func() {
+		res := t.registry.engine.MarkExternalTextureFrameAvailable(t.ID)
+		if res != embedder.KSuccess {
+			log.Printf("failed to mark frame available on texture %d: engine result %d\n", t.ID, res)
+		}
+	}})
+	if err != nil {
+		return errors.Wrapf(err, "failed to mark frame available on texture %d", t.ID)
+	}
+	return nil
+}
+
+// Unregister unregisters the texture, its source isn't called anymore. It is
+// safe to call from any goroutine.
+func (t *Texture) Unregister() error {
+	r := t.registry
+	r.lock.Lock()
+	texture, ok := r.textures[t.ID]
+	if ok {
+		delete(r.textures, t.ID)
+		if texture.name != 0 {
+			r.deleted = append(r.deleted, texture.name)
+		}
+	}
+	r.lock.Unlock()
+	if !ok {
+		return errors.Errorf("texture %d is not registered", t.ID)
+	}
+
+	err := r.tasks.Post(taskqueue.Task{Fn: func() {
+		res := r.engine.UnregisterExternalTexture(t.ID)
+		if res != embedder.KSuccess {
+			log.Printf("failed to unregister texture %d: engine result %d\n", t.ID, res)
+		}
+	}})
+	if err != nil {
+		return errors.Wrapf(err, "failed to unregister texture %d", t.ID)
+	}
+	return nil
+}
+
+// release drops the registered textures once the engine is shut down or has
+// failed to run, their sources aren't kept anymore. The GL textures go with
+// the context of the window.
+func (r *TextureRegistry) release() {
+	r.lock.Lock()
+	r.textures = make(map[int64]*registeredTexture)
+	r.deleted = nil
+	r.lock.Unlock()
+}
+
+// handleFrameCallback satisfies
+// embedder.FlutterEngine.FGLExternalTextureFrameCallback, it uploads the
+// frame of the source to the GL texture.
+func (r *TextureRegistry) handleFrameCallback(textureID int64, width int, height int) (embedder.GLTexture, bool) {
+	if !r.glLoaded {
+		r.loadGL()
+	}
+	if r.glFailed {
+		return embedder.GLTexture{}, false
+	}
+
+	r.lock.Lock()
+	deleted := r.deleted
+	r.deleted = nil
+	texture, ok := r.textures[textureID]
+	var name uint32
+	var allocatedWidth, allocatedHeight int
+	if ok {
+		name, allocatedWidth, allocatedHeight = texture.name, texture.width, texture.height
+	}
+	r.lock.Unlock()
+
+	for _, name := range deleted {
+		C.deleteTexture(&r.gl, C.uint(name))
+	}
+	if !ok {
+		return embedder.GLTexture{}, false
+	}
+
+	frame := texture.source.TextureFrame(width, height)
+	if frame != nil && frame.Rect.Dx() > 0 && frame.Rect.Dy() > 0 {
+		// The storage is only allocated when the size of the frames changes.
+		frameWidth, frameHeight := frame.Rect.Dx(), frame.Rect.Dy()
+		allocate := C.int(0)
+		if name == 0 || frameWidth != allocatedWidth || frameHeight != allocatedHeight {
+			allocate = 1
+		}
+		pixels := frame.Pix[frame.PixOffset(frame.Rect.Min.X, frame.Rect.Min.Y):]
+		name = uint32(C.uploadTexture(&r.gl, C.uint(name), allocate, C.int(frameWidth), C.int(frameHeight),
+			C.int(frame.Stride/4), unsafe.Pointer(&pixels[0])))
+
+		r.lock.Lock()
+		if _, ok := r.textures[textureID]; ok {
+			texture.name, texture.width, texture.height = name, frameWidth, frameHeight
+		} else {
+			// unregistered meanwhile
+			r.deleted = append(r.deleted, name)
+		}
+		r.lock.Unlock()
+	}
+	if name == 0 {
+		// no frame yet
+		return embedder.GLTexture{}, false
+	}
+
+	return embedder.GLTexture{
+		Target: C.GL_TEXTURE_2D,
+		Name:   name,
+		Format: C.GL_RGBA8,
+	}, true
+}
+
+// loadGL resolves the OpenGL functions used to upload the frames. It must be
+// called with the context of the window current.
+func (r *TextureRegistry) loadGL() {
+	r.glLoaded = true
+	procs := []struct {
+		name string
+		proc **[0]byte
+	}{
+		{"glGenTextures", &r.gl.genTextures},
+		{"glDeleteTextures", &r.gl.deleteTextures},
+		{"glBindTexture", &r.gl.bindTexture},
+		{"glTexParameteri", &r.gl.texParameteri},
+		{"glPixelStorei", &r.gl.pixelStorei},
+		{"glGetIntegerv", &r.gl.getIntegerv},
+		{"glTexImage2D", &r.gl.texImage2D},
+		{"glTexSubImage2D", &r.gl.texSubImage2D},
+	}
+	for _, p := range procs {
+		address := r.glProcResolver(p.name)
+		if address == nil {
+			log.Printf("texture registry: OpenGL function %s is unavailable, external textures are not drawn\n", p.name)
+			r.glFailed = true
+			return
+		}
+		*p.proc = (*[0]byte)(address)
+	}
+}
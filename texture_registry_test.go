@@ -0,0 +1,101 @@
+package flutter
+
+import (
+	"image"
+	"testing"
+
+	"github.com/go-flutter-desktop/go-flutter/internal/taskqueue"
+)
+
+type testTextureSource struct{}
+
+func (testTextureSource) TextureFrame(width int, height int) *image.RGBA {
+	return image.NewRGBA(image.Rect(0, 0, width, height))
+}
+
+func TestTextureRegistrySoftwareRenderer(t *testing.T) {
+	tasks := &taskqueue.Queue{}
+	tasks.Start()
+	defer tasks.Stop()
+
+	registry := newTextureRegistry(nil, tasks, nil, true)
+	texture, err := registry.Register(testTextureSource{})
+	if err == nil {
+		t.Fatalf("texture %d registered with the software renderer", texture.ID)
+	}
+
+	registry = newTextureRegistry(nil, tasks, nil, false)
+	texture, err = registry.Register(testTextureSource{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if texture.ID != 1 {
+		t.Fatalf("texture registered with ID %d, expected 1", texture.ID)
+	}
+}
+
+func TestTextureRegistryUnregister(t *testing.T) {
+	tasks := &taskqueue.Queue{}
+	tasks.Start()
+	defer tasks.Stop()
+
+	registry := newTextureRegistry(nil, tasks, nil, false)
+	texture, err := registry.Register(testTextureSource{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = texture.FrameAvailable()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = texture.Unregister()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := registry.textures[texture.ID]; ok {
+		t.Fatalf("texture %d still registered", texture.ID)
+	}
+	err = texture.Unregister()
+	if err == nil {
+		t.Fatalf("texture %d unregistered twice", texture.ID)
+	}
+	err = texture.FrameAvailable()
+	if err == nil {
+		t.Fatalf("frame marked available on the unregistered texture %d", texture.ID)
+	}
+
+	unknown := &Texture{ID: 42, registry: registry}
+	err = unknown.FrameAvailable()
+	if err == nil {
+		t.Fatal("frame marked available on an unknown texture")
+	}
+	err = unknown.Unregister()
+	if err == nil {
+		t.Fatal("unknown texture unregistered")
+	}
+}
+
+func TestTextureRegistryStoppedQueue(t *testing.T) {
+	tasks := &taskqueue.Queue{}
+	tasks.Start()
+
+	registry := newTextureRegistry(nil, tasks, nil, false)
+	texture, err := registry.Register(testTextureSource{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	tasks.Stop()
+
+	err = texture.FrameAvailable()
+	if err == nil {
+		t.Fatal("frame marked available once the queue is stopped")
+	}
+	_, err = registry.Register(testTextureSource{})
+	if err == nil {
+		t.Fatal("texture registered once the queue is stopped")
+	}
+	if len(registry.textures) != 1 {
+		t.Fatalf("%d textures registered, expected the texture registered before the stop", len(registry.textures))
+	}
+}